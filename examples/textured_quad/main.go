@@ -1,14 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"image/png"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/devodev/opengl-experiment/internal/engine"
 	"github.com/devodev/opengl-experiment/internal/engine/application"
+	"github.com/devodev/opengl-experiment/internal/engine/asset"
+	"github.com/devodev/opengl-experiment/internal/engine/input"
 	"github.com/devodev/opengl-experiment/internal/engine/renderer"
-	"github.com/devodev/opengl-experiment/internal/opengl"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -17,68 +22,229 @@ func init() {
 }
 
 func main() {
+	record := flag.String("record", "", "record the input to `file`")
+	replay := flag.String("replay", "", "replay the input recorded in `file`")
+	flag.Parse()
+
 	logger := engine.NewLogger()
-	application.SetLogger(logger)
 
-	layer := &SquareTextureLayer{}
-	application.AddLayer(layer)
+	windowOptions := []window.Option{
+		window.WithDimensionsOption(1024, 768),
+		window.WithVSyncOption(vsyncMode()),
+	}
+	// additional gamepad mappings, such as gamecontrollerdb.txt
+	if path := os.Getenv("GAMEPAD_MAPPINGS"); path != "" {
+		mappings, err := os.ReadFile(path)
+		if err != nil {
+			logger.Errorf("error reading gamepad mappings: %s", err)
+			return
+		}
+		windowOptions = append(windowOptions, window.WithGamepadMappingsOption(string(mappings)))
+	}
 
-	if os.Getenv("PPROF") == "true" {
-		application.EnableProfiling()
+	app, err := application.New(
+		application.WithLoggerOption(logger),
+		application.WithWindowOptions(windowOptions...),
+		application.WithProfilingOption(os.Getenv("PPROF") == "true"),
+		application.WithFixedTimestepOption(60, 0),
+		assetsOption(),
+		inputOption(),
+		application.WithHotReloadOption(500*time.Millisecond),
+		replayOption(*record, *replay),
+	)
+	if err != nil {
+		logger.Errorf("error creating application: %s", err)
+		return
 	}
+	app.PushLayer(&SquareTextureLayer{app: app})
 
-	application.SetWindowSize(1024, 768)
-	if err := application.Run(); err != nil {
+	if err := app.Run(); err != nil {
 		logger.Errorf("error running application: %s", err)
 		return
 	}
 }
 
+// assetsOption loads the assets from the pack file
+// set by ASSET_PACK, or from the assets directory.
+func assetsOption() application.Option {
+	if path := os.Getenv("ASSET_PACK"); path != "" {
+		return application.WithAssetPackOption(path)
+	}
+	return application.WithAssetRootOption("assets")
+}
+
+// inputOption loads the input bindings from the file set by INPUT_CONFIG,
+// such as input.json.
+func inputOption() application.Option {
+	if path := os.Getenv("INPUT_CONFIG"); path != "" {
+		return application.WithInputConfigOption(path)
+	}
+	return func(*application.Application) error { return nil }
+}
+
+// replayOption records the input to the record file, or
+// replays the input recorded in the replay file.
+func replayOption(record, replay string) application.Option {
+	switch {
+	case replay != "":
+		return application.WithInputReplayOption(replay)
+	case record != "":
+		return application.WithInputRecordingOption(record)
+	}
+	return func(*application.Application) error { return nil }
+}
+
+func vsyncMode() window.VSyncMode {
+	switch os.Getenv("VSYNC") {
+	case "true":
+		return window.VSyncOn
+	case "adaptive":
+		return window.VSyncAdaptive
+	}
+	return window.VSyncOff
+}
+
+const (
+	exampleInputContextName = "example"
+	actionScreenshot        = "screenshot"
+)
+
 // SquareTextureLayer .
 type SquareTextureLayer struct {
-	quads []*renderer.TexturedQuad
+	app *application.Application
+
+	textures []*asset.TextureHandle
+	quads    []*renderer.TexturedQuad
+	title    *renderer.Text
+
+	// circle angle simulated at a fixed rate
+	angle     float32
+	prevAngle float32
 
 	cameraController *renderer.CameraController
 }
 
-// OnInit .
-func (c *SquareTextureLayer) OnInit() error {
-	texture1, err := opengl.NewNRGBATexture("assets/textures/google_logo.png")
-	if err != nil {
-		return fmt.Errorf("error creating texture1: %s", err)
+// OnAttach .
+func (c *SquareTextureLayer) OnAttach() error {
+	// textures are decoded in the background and drawn
+	// using a placeholder until they are uploaded
+	assets := c.app.GetAssets()
+	c.textures = []*asset.TextureHandle{
+		assets.Texture("textures/google_logo.png"),
+		assets.Texture("textures/facebook_logo.png"),
+		assets.Texture("textures/instagram_logo.png"),
 	}
-	texture2, err := opengl.NewNRGBATexture("assets/textures/facebook_logo.png")
-	if err != nil {
-		return fmt.Errorf("error creating texture2: %s", err)
+
+	c.quads = []*renderer.TexturedQuad{
+		{Texture: c.textures[0], Transform: mgl32.Translate3D(-0.5, 0, 2)},
+		{Texture: c.textures[1], Transform: mgl32.Translate3D(0.5, 0, 1)},
+		{Texture: c.textures[2], Transform: mgl32.Translate3D(0, 0.5, 0.5)},
 	}
-	texture3, err := opengl.NewNRGBATexture("assets/textures/instagram_logo.png")
+
+	titleFont, err := renderer.NewDefaultSDFFont()
 	if err != nil {
-		return fmt.Errorf("error creating texture3: %s", err)
+		return fmt.Errorf("error creating title font: %s", err)
+	}
+	c.title = &renderer.Text{
+		Value:     "opengl-experiment\ntextured quads",
+		Transform: mgl32.Translate3D(0, 0.85, 0),
+		Size:      0.1,
+		Color:     mgl32.Vec4{1, 1, 1, 1},
+		Align:     renderer.TextAlignCenter,
+		Font:      titleFont,
+		Outline:   &renderer.TextOutline{Width: 0.15, Color: mgl32.Vec4{0, 0, 0, 1}},
+		Shadow:    &renderer.TextShadow{Offset: mgl32.Vec2{0.03, -0.03}, Softness: 0.1, Color: mgl32.Vec4{0, 0, 0, 0.5}},
 	}
 
-	c.quads = []*renderer.TexturedQuad{
-		{Texture: texture1, Transform: mgl32.Translate3D(-0.5, 0, 2)},
-		{Texture: texture2, Transform: mgl32.Translate3D(0.5, 0, 1)},
-		{Texture: texture3, Transform: mgl32.Translate3D(0, 0.5, 0.5)},
+	// bindings loaded from the input config take precedence over the defaults
+	actions := c.app.GetInput()
+	if actions.GetContext(renderer.CameraInputContextName) == nil {
+		actions.AddContext(renderer.CameraInputContext())
+	}
+	if actions.GetContext(exampleInputContextName) == nil {
+		actions.AddContext(input.NewContext(exampleInputContextName).
+			Bind(actionScreenshot, input.Key(window.KeyS, window.ModControl)))
+	}
+	for _, name := range []string{renderer.CameraInputContextName, exampleInputContextName} {
+		if err := actions.PushContext(name); err != nil {
+			return err
+		}
 	}
 
-	w, h := application.GetWindow().GetSize()
+	w, h := c.app.GetWindow().GetSize()
 	// cameraController := renderer.NewCameraController(renderer.NewCameraPerspective(w, h))
 	c.cameraController = renderer.NewCameraController(renderer.NewCameraOrthographic(w, h))
+	c.cameraController.SetActionMap(actions)
 
 	return nil
 }
 
+// OnDetach .
+func (c *SquareTextureLayer) OnDetach() {
+	c.app.GetInput().PopContext()
+	c.app.GetInput().PopContext()
+	for _, t := range c.textures {
+		t.Release()
+	}
+}
+
 // OnUpdate .
 func (c *SquareTextureLayer) OnUpdate(deltaTime float64) {
-	c.cameraController.OnUpdate(application.GetWindow(), deltaTime)
+	c.cameraController.OnUpdate(c.app.GetWindow(), deltaTime)
+	if c.app.GetInput().Down(actionScreenshot) {
+		// the main thread queue is drained once the frame is rendered
+		c.app.RunOnMainThread(c.saveScreenshot)
+	}
+	c.app.GetDebugDraw().DrawAxis(mgl32.Ident4(), 0.25, 0)
+}
+
+// OnFixedUpdate .
+func (c *SquareTextureLayer) OnFixedUpdate(dt float64) {
+	c.prevAngle = c.angle
+	c.angle += float32(dt)
 }
 
 // OnRender .
 func (c *SquareTextureLayer) OnRender(deltaTime float64) {
-	application.GetRenderer().BeginQuad(c.cameraController)
+	c.app.GetRenderer().BeginScene(c.cameraController)
 	for _, q := range c.quads {
-		application.GetRenderer().DrawTexturedQuad(q)
+		c.app.GetRenderer().DrawTexturedQuad(q)
+	}
+	alpha := float32(c.app.GetInterpolationAlpha())
+	angle := c.prevAngle + (c.angle-c.prevAngle)*alpha
+	c.app.GetRenderer().DrawCircleOutline(mgl32.Translate3D(0, -0.5, 0).Mul4(mgl32.Scale3D(0.4, 0.4, 1)), 0.1, mgl32.Vec4{1, 1, 1, 1})
+	hand := mgl32.Rotate3DZ(angle).Mul3x1(mgl32.Vec3{0.18, 0, 0})
+	c.app.GetRenderer().DrawLine(mgl32.Vec3{0, -0.5, 0}, mgl32.Vec3{hand.X(), hand.Y() - 0.5, 0}, 0.01, mgl32.Vec4{1, 1, 1, 1})
+	c.app.GetRenderer().DrawArrow(mgl32.Vec3{-1, -0.8, 0}, mgl32.Vec3{1, -0.8, 0}, 0.01, 0.08, mgl32.Vec4{1, 0.5, 0.2, 1})
+	c.app.GetRenderer().DrawTextStyled(c.title)
+	if progress := c.app.GetAssetLoader().Progress(); !progress.Done() {
+		c.app.GetRenderer().DrawText(fmt.Sprintf("loading %d/%d", progress.Loaded, progress.Total), mgl32.Translate3D(-0.95, -0.95, 0), 0.05, mgl32.Vec4{1, 1, 1, 1})
+	}
+	c.app.GetRenderer().EndScene()
+}
+
+// OnEvent .
+func (c *SquareTextureLayer) OnEvent(e window.Event) bool {
+	switch e := e.(type) {
+	case *window.GamepadConnectedEvent:
+		c.app.GetLogger().Infof("gamepad %d connected: %s", e.Joystick, e.Name)
+	case *window.GamepadDisconnectedEvent:
+		c.app.GetLogger().Infof("gamepad %d disconnected", e.Joystick)
+	}
+	return false
+}
+
+func (c *SquareTextureLayer) saveScreenshot() {
+	name := fmt.Sprintf("screenshot_%s.png", time.Now().Format("20060102_150405"))
+	f, err := os.Create(name)
+	if err != nil {
+		c.app.GetLogger().Errorf("error saving screenshot: %s", err)
+		return
+	}
+	defer f.Close()
+	if err := png.Encode(f, c.app.GetRenderer().Screenshot()); err != nil {
+		c.app.GetLogger().Errorf("error saving screenshot: %s", err)
+		return
 	}
-	application.GetRenderer().EndQuad()
+	c.app.GetLogger().Infof("saved screenshot %s", name)
 }
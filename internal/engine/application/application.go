@@ -3,11 +3,20 @@ package application
 import (
 	"errors"
 	"fmt"
-	"net/http"
-	_ "net/http/pprof"
+	"io/fs"
+	"os"
+	"time"
 
 	"github.com/devodev/opengl-experiment/internal/engine"
+	"github.com/devodev/opengl-experiment/internal/engine/asset"
+	"github.com/devodev/opengl-experiment/internal/engine/asset/pack"
+	"github.com/devodev/opengl-experiment/internal/engine/debugui"
+	"github.com/devodev/opengl-experiment/internal/engine/input"
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
 	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/devodev/opengl-experiment/internal/engine/replay"
+	"github.com/devodev/opengl-experiment/internal/engine/simulation"
+	"github.com/devodev/opengl-experiment/internal/engine/timestep"
 	"github.com/devodev/opengl-experiment/internal/engine/window"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
@@ -17,77 +26,155 @@ import (
 // Errors
 var (
 	ErrAlreadyClosed = errors.New("application already closed")
+	ErrNotRunning    = errors.New("application not running")
 )
 
-var (
-	app *application
-)
-
-func init() {
-	if err := initApp(); err != nil {
-		panic(err)
-	}
+// Application drives the window, the renderer and the layers.
+type Application struct {
+	closeRequested  bool
+	closed          bool
+	debugServerAddr string
+
+	window       *window.Window
+	renderer     *renderer.Renderer
+	debugDraw    *renderer.DebugDraw
+	frameCounter *FrameCounter
+	fixedStep    *timestep.FixedStep
+	simulation   *simulation.Simulation
+	frameLimiter *FrameLimiter
+	profiler     *profiler.Profiler
+	metrics      metrics
+	mainThread   *mainThreadQueue
+	debugServer  *debugServer
+	assetLoader  *asset.Loader
+	assets       *asset.Manager
+	assetRoot    fs.FS
+	assetPack    *pack.Pack
+	input        *input.ActionMap
+	// recordPath is the file the input is recorded to, if any
+	recordPath string
+	recorder   *replay.Writer
+	replay     *replay.Player
+	// hotReloadInterval is the interval at which asset
+	// files are polled for changes, 0 disabling it
+	hotReloadInterval time.Duration
+	debugUI           *debugUILayer
+	logger            *engine.SimpleLogger
+
+	layerStack *LayerStack
 }
 
-func initApp() error {
-	window, err := window.New()
-	if err != nil {
-		return err
+// New creates an application. The window and renderer
+// are initialized when calling Run.
+func New(options ...Option) (*Application, error) {
+	a := &Application{
+		debugDraw:    renderer.NewDebugDraw(),
+		frameCounter: NewFrameCounter(),
+		frameLimiter: NewFrameLimiter(0),
+		profiler:     profiler.New(),
+		mainThread:   newMainThreadQueue(),
+		assetRoot:    os.DirFS("."),
+		logger:       engine.NewLogger(),
+		layerStack:   NewLayerStack(),
+		input:        newEngineActionMap(),
 	}
-	renderer, err := renderer.New()
-	if err != nil {
-		return err
-	}
-	app = &application{
-		window:   window,
-		renderer: renderer,
-		logger:   engine.NewLogger(),
-	}
-	return nil
-}
+	a.debugUI = newDebugUILayer(a)
+	a.frameCounter.SetCallback(a.logFrameStats)
+
+	// close the asset pack opened by an option when failing
+	created := false
+	defer func() {
+		if !created && a.assetPack != nil {
+			a.assetPack.Close()
+		}
+	}()
 
-type application struct {
-	closeRequested   bool
-	profilingEnabled bool
+	for _, opt := range options {
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
 
-	window   *window.Window
-	renderer *renderer.Renderer
-	// imguiCtx *imgui.Context
-	logger *engine.SimpleLogger
+	if a.replay != nil && a.recordPath != "" {
+		return nil, errors.New("cannot record input while replaying a recording")
+	}
 
-	layers []Layer
+	if a.window == nil {
+		w, err := window.New()
+		if err != nil {
+			return nil, fmt.Errorf("error creating window: %s", err)
+		}
+		a.window = w
+	}
+	if a.renderer == nil {
+		r, err := renderer.New()
+		if err != nil {
+			return nil, fmt.Errorf("error creating renderer: %s", err)
+		}
+		a.renderer = r
+	}
+	a.renderer.SetProfiler(a.profiler)
+	a.simulation = simulation.New(a.window, a.input, a.fixedStep)
+	a.simulation.SetProfiler(a.profiler)
+	created = true
+	return a, nil
 }
 
-func (a *application) init() error {
-	if err := app.window.Init(); err != nil {
+func (a *Application) init() error {
+	if err := a.window.Init(); err != nil {
 		return fmt.Errorf("error initializing window: %v", err)
 	}
-	app.logger.Printf("GLFW version: %s", glfw.GetVersionString())
+	a.logger.Printf("GLFW version: %s", glfw.GetVersionString())
+	a.window.SetEventCallback(a.onEvent)
 
-	if err := app.renderer.Init(); err != nil {
+	if err := a.renderer.Init(); err != nil {
 		return fmt.Errorf("error initializing renderer: %v", err)
 	}
-	app.logger.Printf("OpenGL version: %s", gl.GoStr(gl.GetString(gl.VERSION)))
+	a.logger.Printf("OpenGL version: %s", gl.GoStr(gl.GetString(gl.VERSION)))
 
-	// a.imguiCtx = imgui.CreateContext(nil)
-	// a.imguiCtx.SetCurrent()
+	if err := a.debugUI.OnInit(); err != nil {
+		return fmt.Errorf("error initializing debug GUI: %v", err)
+	}
+
+	loader, err := asset.NewLoader(a)
+	if err != nil {
+		return fmt.Errorf("error creating asset loader: %v", err)
+	}
+	a.assetLoader = loader
+	a.assets = asset.NewManager(loader, a.assetRoot)
+	a.assets.SetReloadCallback(func(name string, err error) {
+		if err != nil {
+			a.logger.Errorf("error reloading asset %s: %s", name, err)
+			return
+		}
+		a.logger.Infof("reloaded asset %s", name)
+	})
+	if a.hotReloadInterval > 0 {
+		a.assets.StartHotReload(a.hotReloadInterval)
+	}
 
 	return nil
 }
 
-func (a *application) run() error {
-	a.setupProfiling()
-
-	// init components
-	for _, layer := range a.layers {
-		if err := layer.OnInit(); err != nil {
-			return err
-		}
+func (a *Application) run() error {
+	if err := a.startDebugServer(); err != nil {
+		return err
 	}
+	defer a.stopDebugServer()
+	defer a.mainThread.close()
+
+	// detach layers on exit
+	defer a.layerStack.detachAll()
 
 	// init frame counter
-	frameCounter := NewFrameCounter()
-	frameCounter.Init(glfw.GetTime())
+	a.frameCounter.Init(glfw.GetTime())
+
+	hooks := simulation.Hooks{
+		Source:      a.debugUI.inputSource,
+		Input:       a.onInput,
+		FixedUpdate: a.fixedUpdate,
+		Update:      a.update,
+	}
 
 	// main loop
 	for {
@@ -95,123 +182,312 @@ func (a *application) run() error {
 			break
 		}
 
-		// update frame counter
-		frameCounter.OnUpdate(glfw.GetTime())
-		deltaTime := frameCounter.Delta()
+		// the replay ends once every recorded frame was played
+		var replayFrame *replay.Frame
+		if a.replay != nil {
+			f, ok := a.replay.Next()
+			if !ok {
+				a.logger.Infof("input replay finished after %d frames", a.replay.Frame())
+				break
+			}
+			replayFrame = f
+		}
 
-		a.processInput()
+		// apply layers pushed or popped during the last frame
+		if err := a.layerStack.apply(a.logger); err != nil {
+			return err
+		}
 
-		// poll events (window and input)
-		glfw.PollEvents()
+		a.profiler.BeginFrame()
+		a.renderer.ResetStats()
 
-		// update layers
-		for _, layer := range a.layers {
-			layer.OnUpdate(deltaTime)
+		// update frame counter
+		a.frameCounter.OnUpdate(glfw.GetTime())
+		deltaTime := a.frameCounter.Delta()
+		a.metrics.update(a.frameCounter.Stats(), a.renderer.Stats())
+
+		// poll events (window and input), or replay them with
+		// the recorded delta time, then update layers
+		var replayed *window.InputFrame
+		if replayFrame != nil {
+			deltaTime = replayFrame.Delta
+			replayed = &replayFrame.Input
+		}
+		a.simulation.Step(deltaTime, replayed, hooks)
+		if a.fixedStep != nil {
+			if dropped := a.fixedStep.Dropped(); dropped > 0 {
+				a.logger.Debugf("fixed step: dropped %.4fs of simulation time", dropped)
+			}
 		}
 
 		// render layers
+		endRenderScope := a.profiler.GPUScope("Application.Render")
 		a.renderer.Clear()
-		for _, layer := range a.layers {
-			layer.OnRender(deltaTime)
+		for _, layer := range a.layerStack.layers {
+			if a.layerStack.IsEnabled(layer) {
+				layer.OnRender(deltaTime)
+			}
 		}
 
-		// render imgui
-		//
-		// imgui.NewFrame()
-		// imgui.Render()
-		// drawDta := imgui.RenderedDrawData()
-		// for _, cmd := range drawDta.CommandLists() {
-		// 	// TODO: https://github.com/inkyblackness/imgui-go-examples/blob/main/internal/renderers/OpenGL3.go
-		// }
-
+		// render debug gizmos on top of layers
+		endScope := a.profiler.Scope("DebugDraw.Render")
+		a.debugDraw.Render(a.renderer)
+		a.debugDraw.Update(deltaTime)
+		endScope()
+
+		// render debug GUI on top of everything
+		endScope = a.profiler.Scope("DebugUI.Render")
+		a.debugUI.OnRender(deltaTime)
+		endScope()
+		endRenderScope()
+
+		// run functions posted from other goroutines, after rendering
+		// so that they can read back the frame
+		endScope = a.profiler.Scope("Application.MainThread")
+		a.mainThread.drain()
+		endScope()
+
+		endScope = a.profiler.Scope("Application.SwapBuffers")
 		a.window.GetGLFWWindow().SwapBuffers()
+		endScope()
+		a.profiler.EndFrame()
+
+		// wait for the next frame, at a lower rate when in background
+		a.frameLimiter.Wait(a.window.IsFocused() && !a.window.IsIconified())
 	}
 	return nil
 }
 
-func (a *application) processInput() {
+// logFrameStats logs the frame summary at the info level, shown by
+// default, and the renderer statistics at the debug level.
+func (a *Application) logFrameStats(s FrameStats) {
+	a.logger.Infof("%.2f fps (avg %.4f ms, min %.4f ms, max %.4f ms, p50 %.4f ms, p95 %.4f ms, p99 %.4f ms, %d spikes)",
+		s.FPS, s.Average, s.Min, s.Max, s.P50, s.P95, s.P99, s.Spikes)
+	r := a.renderer.Stats()
+	a.logger.Debugf("renderer: %d draw calls, %d quads, %d vertices, %d indices, %d texture binds, %d shader binds, %d bytes uploaded",
+		r.DrawCalls, r.Quads, r.Vertices, r.Indices, r.TextureBinds, r.ShaderBinds, r.UploadBytes)
+}
+
+// onInput handles the input of the frame, once the actions are updated.
+func (a *Application) onInput(dt float64) {
+	a.recordFrame(dt)
+	a.processInput()
+
+	// start debug GUI frame, layers can declare widgets
+	// during their update and render
+	a.debugUI.OnUpdate(dt)
+}
+
+func (a *Application) update(dt float64) {
+	for _, layer := range a.layerStack.layers {
+		if a.layerStack.IsEnabled(layer) {
+			layer.OnUpdate(dt)
+		}
+	}
+}
+
+func (a *Application) fixedUpdate(dt float64) {
+	for _, layer := range a.layerStack.layers {
+		if !a.layerStack.IsEnabled(layer) {
+			continue
+		}
+		if f, ok := layer.(FixedUpdater); ok {
+			f.OnFixedUpdate(dt)
+		}
+	}
+}
+
+// onEvent dispatches e to the debug GUI first, as it is drawn on top of
+// everything, then to layers in reverse order until it is handled.
+func (a *Application) onEvent(e window.Event) {
+	if a.debugUI.OnEvent(e) {
+		return
+	}
+	layers := a.layerStack.layers
+	for idx := len(layers) - 1; idx >= 0; idx-- {
+		layer := layers[idx]
+		if a.layerStack.IsEnabled(layer) && layer.OnEvent(e) {
+			return
+		}
+	}
+}
+
+func (a *Application) processInput() {
 	// we lost focus, dont process synthetic events
 	if !a.window.IsFocused() {
 		return
 	}
 
 	// close window
-	if a.window.IsKeyPressed(window.KeyEscape) {
+	if a.input.Pressed(ActionQuit) {
 		a.closeRequested = true
 		return
 	}
 
 	// toggle wireframes
-	if a.window.GetKeyDown(window.KeySpace) {
-		var currentPolygonMode int32
-		gl.GetIntegerv(gl.POLYGON_MODE, &currentPolygonMode)
-		gl.PolygonMode(gl.FRONT_AND_BACK, uint32(gl.LINE+(gl.FILL-currentPolygonMode)))
+	if a.input.Down(ActionToggleWireframe) {
+		toggleWireframe()
+	}
+
+	// toggle debug draw
+	if a.input.Down(ActionToggleDebugDraw) {
+		a.debugDraw.Toggle()
+	}
+
+	// toggle debug GUI panels
+	if a.input.Down(ActionToggleDebugUI) {
+		a.debugUI.toggle()
 	}
 }
 
-func (a *application) shouldClose() bool {
+func (a *Application) shouldClose() bool {
 	return a.closeRequested || a.window.ShouldClose()
 }
 
-func (a *application) setupProfiling() {
-	if !a.profilingEnabled {
-		return
+func (a *Application) startDebugServer() error {
+	if a.debugServerAddr == "" {
+		return nil
 	}
+	a.debugServer = newDebugServer(a, a.debugServerAddr)
+	return a.debugServer.start()
+}
 
-	// starts a http server that will serve pprof endpoints
-	// pprof endpoints are registered when importing its package (_ "net/http/pprof")
-	go func() {
-		addr := "localhost:6060"
-		a.logger.Infof("pprof server listening on http://%s", addr)
-		a.logger.Println(http.ListenAndServe(addr, nil))
-	}()
+func (a *Application) stopDebugServer() {
+	if a.debugServer != nil {
+		a.debugServer.shutdown()
+		a.debugServer = nil
+	}
 }
 
-// Run .
-func Run() error {
-	if err := app.init(); err != nil {
+// Run initializes the window and renderer, then runs the
+// main loop until the window is closed or Close is called.
+func (a *Application) Run() error {
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+	defer func() { a.closed = true }()
+
+	if err := a.init(); err != nil {
 		return err
 	}
-	defer app.window.Close()
+	defer a.window.Close()
+	defer a.assetLoader.Close()
+	defer a.assets.StopHotReload()
+	if a.assetPack != nil {
+		defer a.assetPack.Close()
+	}
+	if err := a.startRecording(); err != nil {
+		return err
+	}
+	defer a.stopRecording()
+	a.startReplay()
+
+	return a.run()
+}
+
+// Close requests the main loop to exit at the end of the current frame.
+func (a *Application) Close() {
+	a.closeRequested = true
+}
+
+// PushLayer queues l to be attached on top of the regular layers,
+// below overlays. It can be called before Run or during a frame.
+func (a *Application) PushLayer(l Layer) {
+	a.layerStack.PushLayer(l)
+}
+
+// PushOverlay queues l to be attached on top of the overlays.
+func (a *Application) PushOverlay(l Layer) {
+	a.layerStack.PushOverlay(l)
+}
+
+// PopLayer queues the regular layer l to be detached.
+func (a *Application) PopLayer(l Layer) {
+	a.layerStack.PopLayer(l)
+}
+
+// PopOverlay queues the overlay l to be detached.
+func (a *Application) PopOverlay(l Layer) {
+	a.layerStack.PopOverlay(l)
+}
+
+// GetLayerStack .
+func (a *Application) GetLayerStack() *LayerStack {
+	return a.layerStack
+}
+
+// GetFrameCounter .
+func (a *Application) GetFrameCounter() *FrameCounter {
+	return a.frameCounter
+}
+
+// GetAssets returns the manager caching the assets loaded from the asset root.
+// It is available once Run started, from the OnAttach method of layers.
+func (a *Application) GetAssets() *asset.Manager {
+	return a.assets
+}
+
+// GetAssetLoader returns the loader decoding assets in the background.
+// It is available once Run started, from the OnAttach method of layers.
+func (a *Application) GetAssetLoader() *asset.Loader {
+	return a.assetLoader
+}
 
-	return app.run()
+// GetProfiler .
+func (a *Application) GetProfiler() *profiler.Profiler {
+	return a.profiler
 }
 
-// Close .
-func Close() {
-	app.closeRequested = true
+// GetFrameLimiter .
+func (a *Application) GetFrameLimiter() *FrameLimiter {
+	return a.frameLimiter
 }
 
-// AddLayer .
-func AddLayer(l Layer) {
-	app.layers = append(app.layers, l)
+// GetFixedStep returns nil when the fixed timestep mode is disabled.
+func (a *Application) GetFixedStep() *timestep.FixedStep {
+	return a.fixedStep
+}
+
+// GetInterpolationAlpha returns the fraction of a fixed step elapsed since
+// the last OnFixedUpdate, to be used when rendering to interpolate between
+// the previous and current simulated states. It returns 1 when the fixed
+// timestep mode is disabled.
+func (a *Application) GetInterpolationAlpha() float64 {
+	if a.fixedStep == nil {
+		return 1
+	}
+	return a.fixedStep.Alpha()
 }
 
 // GetWindow .
-func GetWindow() *window.Window {
-	return app.window
+func (a *Application) GetWindow() *window.Window {
+	return a.window
 }
 
 // GetRenderer .
-func GetRenderer() *renderer.Renderer {
-	return app.renderer
+func (a *Application) GetRenderer() *renderer.Renderer {
+	return a.renderer
 }
 
-// SetLogger .
-func SetLogger(logger *engine.SimpleLogger) {
-	app.logger = logger
+// GetDebugDraw .
+func (a *Application) GetDebugDraw() *renderer.DebugDraw {
+	return a.debugDraw
 }
 
-// SetWindow .
-func SetWindow(window *window.Window) {
-	app.window = window
+// GetDebugUI returns the debug GUI context. Widgets can be
+// declared from the OnUpdate and OnRender methods of layers.
+func (a *Application) GetDebugUI() *debugui.Context {
+	return a.debugUI.ctx
 }
 
-// SetWindow .
-func SetWindowSize(width, height int) {
-	app.window.SetSize(width, height)
+// GetInput returns the action map updated every frame after polling events.
+// Its engine context is at the bottom of the stack; layers can add and push
+// contexts of their own.
+func (a *Application) GetInput() *input.ActionMap {
+	return a.input
 }
 
-func EnableProfiling() {
-	app.profilingEnabled = true
+// GetLogger .
+func (a *Application) GetLogger() *engine.SimpleLogger {
+	return a.logger
 }
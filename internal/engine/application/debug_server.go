@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"time"
+
+	"github.com/devodev/opengl-experiment/internal/opengl"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	defaultDebugServerAddr     = "localhost:6060"
+	debugServerShutdownTimeout = 5 * time.Second
+)
+
+// debugServer serves pprof, metrics and introspection endpoints.
+// Handlers touching engine state run on the main thread.
+type debugServer struct {
+	app    *Application
+	addr   string
+	server *http.Server
+}
+
+func newDebugServer(app *Application, addr string) *debugServer {
+	s := &debugServer{app: app, addr: addr}
+
+	// pprof endpoints are registered when importing its package (_ "net/http/pprof")
+	mux := http.NewServeMux()
+	mux.Handle("/debug/pprof/", http.DefaultServeMux)
+	mux.HandleFunc("/metrics", app.serveMetrics)
+	mux.HandleFunc("/debug/layers", s.handleLayers)
+	mux.HandleFunc("/debug/renderer/stats", s.handleRendererStats)
+	mux.HandleFunc("/debug/gl/resources", s.handleGLResources)
+	mux.HandleFunc("/debug/wireframe", s.handleWireframe)
+	mux.HandleFunc("/debug/screenshot", s.handleScreenshot)
+	mux.HandleFunc("/debug/loglevel", s.handleLogLevel)
+
+	s.server = &http.Server{Handler: mux}
+	return s
+}
+
+// start listens on the configured address and serves in the background.
+func (s *debugServer) start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %s", s.addr, err)
+	}
+	s.app.logger.Infof("debug server listening on http://%s", listener.Addr())
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.app.logger.Errorf("error serving debug server: %s", err)
+		}
+	}()
+	return nil
+}
+
+// shutdown waits for active requests to complete.
+func (s *debugServer) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), debugServerShutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.app.logger.Errorf("error shutting down debug server: %s", err)
+	}
+}
+
+func (s *debugServer) handleLayers(w http.ResponseWriter, r *http.Request) {
+	type layerInfo struct {
+		Index   int    `json:"index"`
+		Name    string `json:"name"`
+		Overlay bool   `json:"overlay"`
+		Enabled bool   `json:"enabled"`
+	}
+	var layers []layerInfo
+	err := s.app.DoContext(r.Context(), func() error {
+		stack := s.app.layerStack
+		for idx, layer := range stack.Layers() {
+			layers = append(layers, layerInfo{
+				Index:   idx,
+				Name:    layerName(layer),
+				Overlay: stack.IsOverlay(layer),
+				Enabled: stack.IsEnabled(layer),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, layers)
+}
+
+func (s *debugServer) handleRendererStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.app.renderer.Stats())
+}
+
+func (s *debugServer) handleGLResources(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, opengl.LiveResources())
+}
+
+// handleWireframe returns the wireframe mode on GET and toggles it on POST.
+func (s *debugServer) handleWireframe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	var enabled bool
+	err := s.app.DoContext(r.Context(), func() error {
+		if r.Method == http.MethodPost {
+			toggleWireframe()
+		}
+		enabled = isWireframeEnabled()
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, map[string]bool{"wireframe": enabled})
+}
+
+// handleScreenshot returns the last rendered frame as a PNG image.
+func (s *debugServer) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	var img *image.NRGBA
+	err := s.app.DoContext(r.Context(), func() error {
+		img = s.app.renderer.Screenshot()
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if err := png.Encode(w, img); err != nil {
+		s.app.logger.Errorf("error encoding screenshot: %s", err)
+	}
+}
+
+// handleLogLevel returns the log level on GET and sets it
+// from the level query parameter on PUT or POST.
+func (s *debugServer) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		level, err := logrus.ParseLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		err = s.app.DoContext(r.Context(), func() error {
+			s.app.logger.SetLevel(level)
+			return nil
+		})
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	writeJSON(w, map[string]string{"level": s.app.logger.GetLevel().String()})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
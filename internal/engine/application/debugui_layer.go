@@ -0,0 +1,254 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/devodev/opengl-experiment/internal/engine/debugui"
+	"github.com/devodev/opengl-experiment/internal/engine/input"
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+var (
+	profilerTracePath = "profile_trace.json"
+)
+
+// debugUILayer renders the debug GUI on top of the other layers,
+// along with built-in panels for fps, renderer, layers and profiler.
+type debugUILayer struct {
+	app *Application
+
+	ctx      *debugui.Context
+	renderer *debugui.Renderer
+	visible  bool
+
+	frameTimes []float32
+	histogram  []float32
+
+	glVersion  string
+	glRenderer string
+}
+
+func newDebugUILayer(app *Application) *debugUILayer {
+	return &debugUILayer{app: app}
+}
+
+// OnInit .
+func (l *debugUILayer) OnInit() error {
+	font, err := renderer.NewDefaultFont()
+	if err != nil {
+		return fmt.Errorf("error creating debug GUI font: %v", err)
+	}
+	l.ctx = debugui.NewContext(font)
+	l.renderer = debugui.NewRenderer(font)
+	if err := l.renderer.Init(); err != nil {
+		return fmt.Errorf("error initializing debug GUI renderer: %v", err)
+	}
+
+	l.glVersion = gl.GoStr(gl.GetString(gl.VERSION))
+	l.glRenderer = gl.GoStr(gl.GetString(gl.RENDERER))
+	return nil
+}
+
+// OnUpdate starts a new GUI frame so that layers
+// can declare widgets during their update and render.
+func (l *debugUILayer) OnUpdate(deltaTime float64) {
+	// lay out in screen coordinates, those of the cursor events
+	width, height := l.app.window.GetScreenSize()
+	l.ctx.NewFrame(width, height, deltaTime)
+}
+
+// OnEvent forwards input to the GUI context. Mouse events
+// are handled when the mouse is over a GUI window.
+func (l *debugUILayer) OnEvent(e window.Event) bool {
+	switch e := e.(type) {
+	case *window.MouseButtonPressedEvent:
+		l.ctx.OnMouseButton(e.Button, window.KeyActionPress)
+		return l.visible && l.ctx.WantCaptureMouse()
+	case *window.MouseButtonReleasedEvent:
+		l.ctx.OnMouseButton(e.Button, window.KeyActionRelease)
+		return l.visible && l.ctx.WantCaptureMouse()
+	case *window.MouseMovedEvent:
+		l.ctx.OnCursorPos(e.X, e.Y)
+	case *window.MouseScrolledEvent:
+		l.ctx.OnScroll(e.XOffset, e.YOffset)
+		return l.visible && l.ctx.WantCaptureMouse()
+	case *window.CharTypedEvent:
+		l.ctx.OnChar(e.Char)
+	}
+	return false
+}
+
+// OnRender draws the built-in panels and renders the GUI.
+func (l *debugUILayer) OnRender(deltaTime float64) {
+	if !l.visible {
+		l.ctx.Render()
+		return
+	}
+
+	l.fpsPanel()
+	l.rendererPanel()
+	l.layersPanel()
+	l.profilerPanel()
+
+	width, height := l.app.window.GetScreenSize()
+	fbWidth, fbHeight := l.app.window.GetSize()
+	// the GUI pass is part of the renderer statistics
+	l.app.renderer.AddStats(l.renderer.Render(l.ctx.Render(), width, height, fbWidth, fbHeight))
+}
+
+// inputSource returns the source the actions are computed from, hiding
+// the mouse while the GUI uses it, unless the cursor is disabled.
+func (l *debugUILayer) inputSource(src input.Source) input.Source {
+	if l.visible && l.ctx.WantCaptureMouse() && l.app.window.GetCursorMode() != window.CursorDisabled {
+		return input.WithoutMouse(src)
+	}
+	return src
+}
+
+func (l *debugUILayer) toggle() {
+	l.visible = !l.visible
+}
+
+func (l *debugUILayer) fpsPanel() {
+	if l.ctx.Begin("FPS") {
+		fc := l.app.frameCounter
+		stats := fc.Stats()
+		l.ctx.Text("%.2f fps (%.4f ms/frame)", fc.FPS(), fc.FrameTime())
+		l.ctx.Text("avg %.2f  min %.2f  max %.2f", stats.Average, stats.Min, stats.Max)
+		l.ctx.Text("p50 %.2f  p95 %.2f  p99 %.2f", stats.P50, stats.P95, stats.P99)
+		l.ctx.Text("spikes %d (total %d)", stats.Spikes, fc.TotalSpikes())
+
+		l.frameTimes = l.frameTimes[:0]
+		var max float32
+		for _, t := range fc.FrameTimes() {
+			l.frameTimes = append(l.frameTimes, float32(t))
+			if float32(t) > max {
+				max = float32(t)
+			}
+		}
+		l.ctx.PlotLines(fmt.Sprintf("frame time (max %.2f ms)", max), l.frameTimes, 0, max*1.2, 60)
+
+		l.histogram = l.histogram[:0]
+		var maxCount float32
+		histogram, _ := fc.Histogram(1, 34)
+		for _, c := range histogram {
+			l.histogram = append(l.histogram, float32(c))
+			if float32(c) > maxCount {
+				maxCount = float32(c)
+			}
+		}
+		l.ctx.PlotLines("histogram (0-33 ms)", l.histogram, 0, maxCount, 40)
+
+		if f := l.app.fixedStep; f != nil {
+			l.ctx.Text("fixed step: %.0f Hz, %d steps, alpha %.2f", f.TickRate(), f.Steps(), f.Alpha())
+		}
+	}
+	l.ctx.End()
+}
+
+func (l *debugUILayer) rendererPanel() {
+	if l.ctx.Begin("Renderer") {
+		l.ctx.TextDisabled("%s", l.glRenderer)
+		l.ctx.TextDisabled("OpenGL %s", l.glVersion)
+		width, height := l.app.window.GetSize()
+		l.ctx.Text("viewport: %dx%d", width, height)
+		l.ctx.Separator()
+
+		stats := l.app.renderer.Stats()
+		l.ctx.Text("draw calls: %d, quads: %d", stats.DrawCalls, stats.Quads)
+		l.ctx.Text("vertices: %d, indices: %d", stats.Vertices, stats.Indices)
+		l.ctx.Text("texture binds: %d, shader binds: %d", stats.TextureBinds, stats.ShaderBinds)
+		l.ctx.Text("uploaded: %.1f KiB", float64(stats.UploadBytes)/1024)
+		l.ctx.Separator()
+
+		wireframe := isWireframeEnabled()
+		if l.ctx.Checkbox("wireframe", &wireframe) {
+			toggleWireframe()
+		}
+		vsync := l.app.window.VSync() != window.VSyncOff
+		if l.ctx.Checkbox("vsync", &vsync) {
+			if vsync {
+				l.app.window.SetVSync(window.VSyncOn)
+			} else {
+				l.app.window.SetVSync(window.VSyncOff)
+			}
+		}
+		targetFPS := float32(l.app.frameLimiter.TargetFPS())
+		if l.ctx.SliderFloat("target fps", &targetFPS, 0, 240) {
+			l.app.frameLimiter.SetTargetFPS(float64(int(targetFPS)))
+		}
+		debugDraw := l.app.debugDraw.Enabled()
+		if l.ctx.Checkbox("debug draw", &debugDraw) {
+			l.app.debugDraw.SetEnabled(debugDraw)
+		}
+	}
+	l.ctx.End()
+}
+
+func (l *debugUILayer) layersPanel() {
+	if l.ctx.Begin("Layers") {
+		stack := l.app.layerStack
+		for idx, layer := range stack.Layers() {
+			label := fmt.Sprintf("%d: %s", idx, layerName(layer))
+			if stack.IsOverlay(layer) {
+				label += " (overlay)"
+			}
+			enabled := stack.IsEnabled(layer)
+			if l.ctx.Checkbox(label, &enabled) {
+				stack.SetEnabled(layer, enabled)
+			}
+		}
+	}
+	l.ctx.End()
+}
+
+func (l *debugUILayer) profilerPanel() {
+	if l.ctx.Begin("Profiler") {
+		p := l.app.profiler
+		enabled := p.Enabled()
+		if l.ctx.Checkbox("enabled", &enabled) {
+			p.SetEnabled(enabled)
+		}
+		l.ctx.SameLine()
+		if l.ctx.Button("export trace") {
+			if err := p.SaveChromeTrace(profilerTracePath); err != nil {
+				l.app.logger.Errorf("error exporting profiler trace: %s", err)
+			} else {
+				l.app.logger.Infof("profiler trace exported to %s", profilerTracePath)
+			}
+		}
+
+		frame := p.LastFrame()
+		l.ctx.Text("frame %d: %.3f ms", frame.Frame, durationMs(frame.Duration))
+		l.ctx.Separator()
+		l.ctx.TextDisabled("CPU")
+		for _, s := range frame.CPU {
+			l.ctx.Text("%-24s %7.3f ms (%d)", s.Name, durationMs(s.Total), s.Count)
+		}
+		l.ctx.Separator()
+		l.ctx.TextDisabled("GPU")
+		for _, s := range frame.GPU {
+			l.ctx.Text("%-24s %7.3f ms (%d)", s.Name, durationMs(s.Total), s.Count)
+		}
+	}
+	l.ctx.End()
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e6
+}
+
+func isWireframeEnabled() bool {
+	var currentPolygonMode int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &currentPolygonMode)
+	return currentPolygonMode == gl.LINE
+}
+
+func toggleWireframe() {
+	var currentPolygonMode int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &currentPolygonMode)
+	gl.PolygonMode(gl.FRONT_AND_BACK, uint32(gl.LINE+(gl.FILL-currentPolygonMode)))
+}
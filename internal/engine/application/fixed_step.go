@@ -0,0 +1,7 @@
+package application
+
+// FixedUpdater can be implemented by layers to be updated at
+// the fixed tick rate, when the fixed timestep mode is enabled.
+type FixedUpdater interface {
+	OnFixedUpdate(dt float64)
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"time"
+)
+
+var (
+	defaultUnfocusedFPS = 30.0
+	// frameLimiterSpinThreshold is the time before a deadline at which the
+	// limiter stops sleeping and spins, as sleep is not precise enough.
+	frameLimiterSpinThreshold = 2 * time.Millisecond
+)
+
+// FrameLimiter caps the frame rate by waiting at the end of each frame.
+// A lower rate is used when the window is unfocused or iconified.
+type FrameLimiter struct {
+	targetFPS    float64
+	unfocusedFPS float64
+
+	nextFrame time.Time
+}
+
+// NewFrameLimiter creates a limiter capping the frame rate to targetFPS.
+// A targetFPS of 0 leaves the frame rate unlimited while focused.
+func NewFrameLimiter(targetFPS float64) *FrameLimiter {
+	return &FrameLimiter{
+		targetFPS:    targetFPS,
+		unfocusedFPS: defaultUnfocusedFPS,
+	}
+}
+
+// SetTargetFPS sets the frame rate cap while focused, 0 meaning unlimited.
+func (f *FrameLimiter) SetTargetFPS(fps float64) {
+	f.targetFPS = fps
+}
+
+// TargetFPS .
+func (f *FrameLimiter) TargetFPS() float64 {
+	return f.targetFPS
+}
+
+// SetUnfocusedFPS sets the frame rate cap while unfocused or
+// iconified, 0 meaning the focused cap is used.
+func (f *FrameLimiter) SetUnfocusedFPS(fps float64) {
+	f.unfocusedFPS = fps
+}
+
+// UnfocusedFPS .
+func (f *FrameLimiter) UnfocusedFPS() float64 {
+	return f.unfocusedFPS
+}
+
+// Wait blocks until the next frame is due.
+func (f *FrameLimiter) Wait(focused bool) {
+	fps := f.targetFPS
+	if !focused && f.unfocusedFPS > 0 && (fps <= 0 || f.unfocusedFPS < fps) {
+		fps = f.unfocusedFPS
+	}
+	now := time.Now()
+	if fps <= 0 {
+		f.nextFrame = now
+		return
+	}
+	period := time.Duration(float64(time.Second) / fps)
+
+	// schedule from the previous deadline to keep a steady rate,
+	// unless we fell behind by more than a frame
+	f.nextFrame = f.nextFrame.Add(period)
+	if now.Sub(f.nextFrame) > period {
+		f.nextFrame = now.Add(period)
+	}
+
+	if remaining := f.nextFrame.Sub(now) - frameLimiterSpinThreshold; remaining > 0 {
+		time.Sleep(remaining)
+	}
+	// spin for the remaining time
+	for time.Now().Before(f.nextFrame) {
+	}
+}
@@ -1,34 +1,104 @@
 package application
 
 import (
-	"fmt"
+	"errors"
+	"math"
+	"sort"
 )
 
 var (
-	defaultPrintDeltaSeconds = 1.0
+	defaultReportDeltaSeconds = 1.0
+	defaultFrameHistory       = 240
+	// a frame is a spike when it takes longer than
+	// defaultSpikeFactor times the median frame time
+	defaultSpikeFactor = 2.0
 )
 
+// FrameStats are frame time statistics, in milliseconds,
+// computed over the frame history.
+type FrameStats struct {
+	FPS     float64
+	Average float64
+	Min     float64
+	Max     float64
+	P50     float64
+	P95     float64
+	P99     float64
+	// Spikes is the number of spikes since the last report.
+	Spikes int
+	// Samples is the number of frames in the history.
+	Samples int
+}
+
+// FrameStatsCallback receives the frame statistics at every report interval.
+type FrameStatsCallback func(FrameStats)
+
 // FrameCounter .
 type FrameCounter struct {
-	printDeltaSeconds float64
+	reportDeltaSeconds float64
+	spikeFactor        float64
+	callback           FrameStatsCallback
+
+	deltaTime    float64
+	lastTime     float64
+	reportTime   float64
+	reportFrames int
+
+	// ring buffer of frame times, in milliseconds
+	frameTimes []float64
+	head       int
+	count      int
+
+	spikes      int
+	totalSpikes int
 
-	deltaTime   float64
-	lastTime    float64
-	fpsLastTime float64
-	nbFrames    int
+	// last measured values
+	fps       float64
+	frameTime float64
+	stats     FrameStats
 }
 
 // NewFrameCounter .
 func NewFrameCounter() *FrameCounter {
 	return &FrameCounter{
-		printDeltaSeconds: defaultPrintDeltaSeconds,
+		reportDeltaSeconds: defaultReportDeltaSeconds,
+		spikeFactor:        defaultSpikeFactor,
+		frameTimes:         make([]float64, defaultFrameHistory),
+	}
+}
+
+// SetCallback sets the callback receiving the statistics at every report interval.
+func (f *FrameCounter) SetCallback(fn FrameStatsCallback) {
+	f.callback = fn
+}
+
+// SetReportInterval sets the interval between reports, in seconds.
+func (f *FrameCounter) SetReportInterval(seconds float64) {
+	f.reportDeltaSeconds = seconds
+}
+
+// SetHistorySize sets the number of frames kept in the history,
+// discarding the current history.
+func (f *FrameCounter) SetHistorySize(size int) error {
+	if size < 1 {
+		return errors.New("frame history size must be positive")
 	}
+	f.frameTimes = make([]float64, size)
+	f.head = 0
+	f.count = 0
+	return nil
+}
+
+// SetSpikeFactor sets the ratio to the median frame time
+// above which a frame is counted as a spike.
+func (f *FrameCounter) SetSpikeFactor(factor float64) {
+	f.spikeFactor = factor
 }
 
 // Init .
 func (f *FrameCounter) Init(currentTime float64) {
 	f.lastTime = currentTime
-	f.fpsLastTime = currentTime
+	f.reportTime = currentTime
 }
 
 // OnUpdate .
@@ -36,19 +106,133 @@ func (f *FrameCounter) OnUpdate(currentTime float64) {
 	f.deltaTime = currentTime - f.lastTime
 	f.lastTime = currentTime
 
-	f.nbFrames++
-	delta := currentTime - f.fpsLastTime
-	if delta >= f.printDeltaSeconds {
-		fps := float64(f.nbFrames) / delta
-		frameTime := (delta * 1000) / float64(f.nbFrames)
-		fmt.Printf("%.2f fps (%.4f ms/frame)\n", fps, frameTime)
+	frameTime := f.deltaTime * 1000
+	if f.count > 0 && frameTime > f.stats.P50*f.spikeFactor && f.stats.P50 > 0 {
+		f.spikes++
+		f.totalSpikes++
+	}
+	f.push(frameTime)
+
+	f.reportFrames++
+	delta := currentTime - f.reportTime
+	if delta >= f.reportDeltaSeconds {
+		f.fps = float64(f.reportFrames) / delta
+		f.frameTime = (delta * 1000) / float64(f.reportFrames)
+
+		f.stats = f.computeStats()
+		if f.callback != nil {
+			f.callback(f.stats)
+		}
+
+		f.spikes = 0
+		f.reportFrames = 0
+		f.reportTime += delta
+	}
+}
+
+func (f *FrameCounter) push(frameTime float64) {
+	if len(f.frameTimes) == 0 {
+		return
+	}
+	f.frameTimes[f.head] = frameTime
+	f.head = (f.head + 1) % len(f.frameTimes)
+	if f.count < len(f.frameTimes) {
+		f.count++
+	}
+}
+
+func (f *FrameCounter) computeStats() FrameStats {
+	stats := FrameStats{
+		FPS:     f.fps,
+		Spikes:  f.spikes,
+		Samples: f.count,
+	}
+	if f.count == 0 {
+		return stats
+	}
+	sorted := f.history()
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, t := range sorted {
+		sum += t
+	}
+	stats.Average = sum / float64(len(sorted))
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.P50 = percentile(sorted, 0.50)
+	stats.P95 = percentile(sorted, 0.95)
+	stats.P99 = percentile(sorted, 0.99)
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
 
-		f.nbFrames = 0
-		f.fpsLastTime += delta
+// history returns the frame times in the history, from oldest to newest.
+func (f *FrameCounter) history() []float64 {
+	times := make([]float64, 0, f.count)
+	start := (f.head - f.count + len(f.frameTimes)) % len(f.frameTimes)
+	for i := 0; i < f.count; i++ {
+		times = append(times, f.frameTimes[(start+i)%len(f.frameTimes)])
 	}
+	return times
 }
 
 // GetDelta .
 func (f *FrameCounter) Delta() float64 {
 	return f.deltaTime
 }
+
+// FPS returns the frame rate measured over the last report interval.
+func (f *FrameCounter) FPS() float64 {
+	return f.fps
+}
+
+// FrameTime returns the average frame time, in milliseconds,
+// measured over the last report interval.
+func (f *FrameCounter) FrameTime() float64 {
+	return f.frameTime
+}
+
+// Stats returns the statistics computed at the last report interval.
+func (f *FrameCounter) Stats() FrameStats {
+	return f.stats
+}
+
+// TotalSpikes returns the number of spikes since Init.
+func (f *FrameCounter) TotalSpikes() int {
+	return f.totalSpikes
+}
+
+// FrameTimes returns the frame times in the history, in milliseconds,
+// from oldest to newest.
+func (f *FrameCounter) FrameTimes() []float64 {
+	return f.history()
+}
+
+// Histogram distributes the frame times in the history into buckets
+// of width bucketMs milliseconds. The last bucket holds all the frame
+// times above its lower bound.
+func (f *FrameCounter) Histogram(bucketMs float64, buckets int) ([]int, error) {
+	if buckets < 1 || bucketMs <= 0 {
+		return nil, errors.New("histogram buckets and bucket width must be positive")
+	}
+	counts := make([]int, buckets)
+	for _, t := range f.history() {
+		idx := int(t / bucketMs)
+		if idx < 0 {
+			idx = 0
+		} else if idx >= buckets {
+			idx = buckets - 1
+		}
+		counts[idx]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"github.com/devodev/opengl-experiment/internal/engine/input"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Engine input context and actions, bound by default
+// and handled by the application itself
+const (
+	EngineInputContextName = "engine"
+
+	ActionQuit            = "quit"
+	ActionToggleWireframe = "toggle_wireframe"
+	ActionToggleDebugDraw = "toggle_debug_draw"
+	ActionToggleDebugUI   = "toggle_debug_ui"
+)
+
+// EngineInputContext returns the default bindings of the engine actions.
+func EngineInputContext() *input.Context {
+	return input.NewContext(EngineInputContextName).
+		Bind(ActionQuit, input.Key(window.KeyEscape, 0)).
+		Bind(ActionToggleWireframe, input.Key(window.KeySpace, 0)).
+		Bind(ActionToggleDebugDraw, input.Key(window.KeyF1, 0)).
+		Bind(ActionToggleDebugUI, input.Key(window.KeyF2, 0))
+}
+
+func newEngineActionMap() *input.ActionMap {
+	m := input.NewActionMap()
+	m.AddContext(EngineInputContext())
+	m.PushContext(EngineInputContextName)
+	return m
+}
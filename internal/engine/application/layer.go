@@ -1,8 +1,35 @@
 package application
 
+import (
+	"fmt"
+
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
 // Layer .
 type Layer interface {
-	OnInit() error
+	// OnAttach is called when the layer is pushed on the layer stack,
+	// between frames and with the OpenGL context current.
+	OnAttach() error
+	// OnDetach is called when the layer is popped from the layer stack,
+	// or when the application exits.
+	OnDetach()
 	OnUpdate(float64)
 	OnRender(float64)
+	// OnEvent receives window events, starting from the last added layer.
+	// Returning true marks the event as handled and stops its propagation.
+	OnEvent(window.Event) bool
+}
+
+// Namer can be implemented by layers to provide
+// the name displayed in debug tools.
+type Namer interface {
+	Name() string
+}
+
+func layerName(l Layer) string {
+	if n, ok := l.(Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", l)
 }
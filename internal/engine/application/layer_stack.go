@@ -0,0 +1,156 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/devodev/opengl-experiment/internal/engine"
+)
+
+type layerOpKind int
+
+const (
+	layerOpPushLayer layerOpKind = iota
+	layerOpPushOverlay
+	layerOpPopLayer
+	layerOpPopOverlay
+)
+
+type layerOp struct {
+	kind  layerOpKind
+	layer Layer
+}
+
+// LayerStack holds the layers of the application, from bottom to top.
+// Overlays are always kept above regular layers.
+//
+// Push and pop operations are queued and applied between frames,
+// so they can be issued at any time, including from a layer callback.
+type LayerStack struct {
+	layers []Layer
+	// overlayIndex is the index of the first overlay in layers
+	overlayIndex int
+	disabled     map[Layer]bool
+
+	pending []layerOp
+}
+
+// NewLayerStack .
+func NewLayerStack() *LayerStack {
+	return &LayerStack{disabled: make(map[Layer]bool)}
+}
+
+// PushLayer queues l to be attached on top of the regular layers.
+func (s *LayerStack) PushLayer(l Layer) {
+	s.pending = append(s.pending, layerOp{kind: layerOpPushLayer, layer: l})
+}
+
+// PushOverlay queues l to be attached on top of the overlays.
+func (s *LayerStack) PushOverlay(l Layer) {
+	s.pending = append(s.pending, layerOp{kind: layerOpPushOverlay, layer: l})
+}
+
+// PopLayer queues the regular layer l to be detached.
+func (s *LayerStack) PopLayer(l Layer) {
+	s.pending = append(s.pending, layerOp{kind: layerOpPopLayer, layer: l})
+}
+
+// PopOverlay queues the overlay l to be detached.
+func (s *LayerStack) PopOverlay(l Layer) {
+	s.pending = append(s.pending, layerOp{kind: layerOpPopOverlay, layer: l})
+}
+
+// SetEnabled enables or disables l. Disabled layers stay
+// attached but are not updated, rendered or sent events.
+func (s *LayerStack) SetEnabled(l Layer, enabled bool) {
+	if enabled {
+		delete(s.disabled, l)
+	} else {
+		s.disabled[l] = true
+	}
+}
+
+// IsEnabled .
+func (s *LayerStack) IsEnabled(l Layer) bool {
+	return !s.disabled[l]
+}
+
+// IsOverlay reports whether l is an attached overlay.
+func (s *LayerStack) IsOverlay(l Layer) bool {
+	for _, overlay := range s.layers[s.overlayIndex:] {
+		if overlay == l {
+			return true
+		}
+	}
+	return false
+}
+
+// Layers returns the attached layers, from bottom to top.
+func (s *LayerStack) Layers() []Layer {
+	layers := make([]Layer, len(s.layers))
+	copy(layers, s.layers)
+	return layers
+}
+
+// apply attaches and detaches the layers of queued operations, in order.
+// Pushing a layer already attached is logged and skipped, and popping
+// a layer which is not attached is ignored.
+func (s *LayerStack) apply(logger *engine.SimpleLogger) error {
+	for len(s.pending) > 0 {
+		op := s.pending[0]
+		s.pending = s.pending[1:]
+
+		switch op.kind {
+		case layerOpPushLayer, layerOpPushOverlay:
+			if s.index(op.layer) >= 0 {
+				logger.Warnf("layer %s is already attached, ignoring push", layerName(op.layer))
+				continue
+			}
+			if err := op.layer.OnAttach(); err != nil {
+				return fmt.Errorf("error attaching layer %s: %s", layerName(op.layer), err)
+			}
+			if op.kind == layerOpPushOverlay {
+				s.layers = append(s.layers, op.layer)
+				continue
+			}
+			s.layers = append(s.layers, nil)
+			copy(s.layers[s.overlayIndex+1:], s.layers[s.overlayIndex:])
+			s.layers[s.overlayIndex] = op.layer
+			s.overlayIndex++
+		case layerOpPopLayer, layerOpPopOverlay:
+			idx := s.index(op.layer)
+			isOverlay := idx >= s.overlayIndex
+			if idx < 0 || isOverlay != (op.kind == layerOpPopOverlay) {
+				continue
+			}
+			s.remove(idx)
+		}
+	}
+	return nil
+}
+
+// detachAll detaches every layer, from top to bottom.
+func (s *LayerStack) detachAll() {
+	s.pending = nil
+	for idx := len(s.layers) - 1; idx >= 0; idx-- {
+		s.remove(idx)
+	}
+}
+
+func (s *LayerStack) remove(idx int) {
+	l := s.layers[idx]
+	s.layers = append(s.layers[:idx], s.layers[idx+1:]...)
+	if idx < s.overlayIndex {
+		s.overlayIndex--
+	}
+	delete(s.disabled, l)
+	l.OnDetach()
+}
+
+func (s *LayerStack) index(l Layer) int {
+	for idx, layer := range s.layers {
+		if layer == l {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	defaultMainThreadBudget = 4 * time.Millisecond
+)
+
+// mainThreadQueue holds the functions to run on the main thread.
+type mainThreadQueue struct {
+	mu    sync.Mutex
+	funcs []func()
+	// done is closed when the main loop exits
+	done chan struct{}
+
+	// budget is the time allowed to run functions every frame
+	budget time.Duration
+}
+
+func newMainThreadQueue() *mainThreadQueue {
+	return &mainThreadQueue{
+		done:   make(chan struct{}),
+		budget: defaultMainThreadBudget,
+	}
+}
+
+func (q *mainThreadQueue) post(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.funcs = append(q.funcs, fn)
+}
+
+// drain runs the queued functions in order, until the queue is empty
+// or the budget is exhausted. At least one function runs every frame
+// so that the queue always makes progress.
+func (q *mainThreadQueue) drain() {
+	start := time.Now()
+	for {
+		q.mu.Lock()
+		if len(q.funcs) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		fn := q.funcs[0]
+		q.funcs[0] = nil
+		q.funcs = q.funcs[1:]
+		q.mu.Unlock()
+
+		fn()
+
+		if time.Since(start) >= q.budget {
+			return
+		}
+	}
+}
+
+// len returns the number of queued functions.
+func (q *mainThreadQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.funcs)
+}
+
+// close stops waiting for results once the main loop exits.
+func (q *mainThreadQueue) close() {
+	close(q.done)
+}
+
+// do runs fn on the main thread and waits for its result,
+// until ctx is done or the main loop exits.
+func (q *mainThreadQueue) do(ctx context.Context, fn func() error) error {
+	result := make(chan error, 1)
+	q.post(func() {
+		result <- fn()
+	})
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.done:
+		return ErrNotRunning
+	}
+}
+
+// RunOnMainThread queues fn to run on the main thread, where GL calls
+// are allowed. Queued functions run in order at the end of a frame,
+// within the per-frame budget. It is safe to call from any goroutine.
+func (a *Application) RunOnMainThread(fn func()) {
+	a.mainThread.post(fn)
+}
+
+// Do runs fn on the main thread and waits for its result. It returns
+// ErrNotRunning if the main loop exits before fn runs. It must not be
+// called from the main thread, as it would wait forever.
+func (a *Application) Do(fn func() error) error {
+	return a.mainThread.do(context.Background(), fn)
+}
+
+// DoContext is like Do, but stops waiting when ctx is done.
+// In that case, fn may still run later.
+func (a *Application) DoContext(ctx context.Context, fn func() error) error {
+	return a.mainThread.do(ctx, fn)
+}
+
+// PendingMainThreadTasks returns the number of functions waiting to run on the main thread.
+func (a *Application) PendingMainThreadTasks() int {
+	return a.mainThread.len()
+}
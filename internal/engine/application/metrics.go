@@ -0,0 +1,60 @@
+package application
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+)
+
+// metrics is a snapshot of the frame statistics,
+// readable from the debug server goroutines.
+type metrics struct {
+	mu       sync.Mutex
+	frame    FrameStats
+	renderer renderer.Stats
+}
+
+func (m *metrics) update(frame FrameStats, renderer renderer.Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.frame = frame
+	m.renderer = renderer
+}
+
+func (m *metrics) snapshot() (FrameStats, renderer.Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.frame, m.renderer
+}
+
+// serveMetrics writes the metrics using the Prometheus text exposition format.
+func (a *Application) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	frame, stats := a.metrics.snapshot()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeGauge(w, "renderer_draw_calls", "Draw calls of the last frame.", float64(stats.DrawCalls))
+	writeGauge(w, "renderer_quads", "Quads drawn during the last frame.", float64(stats.Quads))
+	writeGauge(w, "renderer_vertices", "Vertices drawn during the last frame.", float64(stats.Vertices))
+	writeGauge(w, "renderer_indices", "Indices drawn during the last frame.", float64(stats.Indices))
+	writeGauge(w, "renderer_texture_binds", "Texture binds of the last frame.", float64(stats.TextureBinds))
+	writeGauge(w, "renderer_shader_binds", "Shader binds of the last frame.", float64(stats.ShaderBinds))
+	writeGauge(w, "renderer_upload_bytes", "Vertex and index bytes uploaded during the last frame.", float64(stats.UploadBytes))
+	writeGauge(w, "frame_fps", "Frames per second.", frame.FPS)
+
+	fmt.Fprintln(w, "# HELP frame_time_milliseconds Frame time over the frame history.")
+	fmt.Fprintln(w, "# TYPE frame_time_milliseconds summary")
+	fmt.Fprintf(w, "frame_time_milliseconds{quantile=\"0.5\"} %g\n", frame.P50)
+	fmt.Fprintf(w, "frame_time_milliseconds{quantile=\"0.95\"} %g\n", frame.P95)
+	fmt.Fprintf(w, "frame_time_milliseconds{quantile=\"0.99\"} %g\n", frame.P99)
+	fmt.Fprintf(w, "frame_time_milliseconds_sum %g\n", frame.Average*float64(frame.Samples))
+	fmt.Fprintf(w, "frame_time_milliseconds_count %d\n", frame.Samples)
+}
+
+func writeGauge(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(w, "%s %g\n", name, value)
+}
@@ -0,0 +1,255 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/devodev/opengl-experiment/internal/engine"
+	"github.com/devodev/opengl-experiment/internal/engine/asset/pack"
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/devodev/opengl-experiment/internal/engine/replay"
+	"github.com/devodev/opengl-experiment/internal/engine/timestep"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Option .
+type Option func(*Application) error
+
+// WithLoggerOption .
+func WithLoggerOption(logger *engine.SimpleLogger) Option {
+	return func(a *Application) error {
+		if logger == nil {
+			return errors.New("logger is nil")
+		}
+		a.logger = logger
+		return nil
+	}
+}
+
+// WithWindowOption sets the window used instead of creating
+// a default one. It must not be initialized.
+func WithWindowOption(w *window.Window) Option {
+	return func(a *Application) error {
+		if w == nil {
+			return errors.New("window is nil")
+		}
+		a.window = w
+		return nil
+	}
+}
+
+// WithWindowOptions creates the window using options.
+func WithWindowOptions(options ...window.Option) Option {
+	return func(a *Application) error {
+		w, err := window.New(options...)
+		if err != nil {
+			return err
+		}
+		a.window = w
+		return nil
+	}
+}
+
+// WithRendererOption sets the renderer used instead of creating
+// a default one. It must not be initialized.
+func WithRendererOption(r *renderer.Renderer) Option {
+	return func(a *Application) error {
+		if r == nil {
+			return errors.New("renderer is nil")
+		}
+		a.renderer = r
+		return nil
+	}
+}
+
+// WithProfilingOption starts the debug server on its default
+// address when running, unless an address is already set.
+func WithProfilingOption(enabled bool) Option {
+	return func(a *Application) error {
+		if !enabled {
+			a.debugServerAddr = ""
+		} else if a.debugServerAddr == "" {
+			a.debugServerAddr = defaultDebugServerAddr
+		}
+		return nil
+	}
+}
+
+// WithDebugServerOption starts the debug server on addr when running.
+// It serves pprof, metrics and introspection endpoints.
+func WithDebugServerOption(addr string) Option {
+	return func(a *Application) error {
+		if addr == "" {
+			return errors.New("debug server address is empty")
+		}
+		a.debugServerAddr = addr
+		return nil
+	}
+}
+
+// WithFixedTimestepOption enables the fixed timestep mode: layers
+// implementing FixedUpdater are updated tickRate times per second,
+// running at most maxSteps steps per frame.
+func WithFixedTimestepOption(tickRate float64, maxSteps int) Option {
+	return func(a *Application) error {
+		f, err := timestep.NewFixedStep(tickRate, maxSteps)
+		if err != nil {
+			return err
+		}
+		a.fixedStep = f
+		return nil
+	}
+}
+
+// WithTargetFPSOption caps the frame rate while the window is focused.
+// A fps of 0 leaves it unlimited.
+func WithTargetFPSOption(fps float64) Option {
+	return func(a *Application) error {
+		if fps < 0 {
+			return errors.New("target fps must not be negative")
+		}
+		a.frameLimiter.SetTargetFPS(fps)
+		return nil
+	}
+}
+
+// WithUnfocusedFPSOption caps the frame rate while the window is unfocused
+// or iconified. A fps of 0 uses the focused cap.
+func WithUnfocusedFPSOption(fps float64) Option {
+	return func(a *Application) error {
+		if fps < 0 {
+			return errors.New("unfocused fps must not be negative")
+		}
+		a.frameLimiter.SetUnfocusedFPS(fps)
+		return nil
+	}
+}
+
+// WithFrameStatsCallbackOption sets the callback receiving frame
+// statistics every second, replacing the default debug log.
+func WithFrameStatsCallbackOption(fn FrameStatsCallback) Option {
+	return func(a *Application) error {
+		a.frameCounter.SetCallback(fn)
+		return nil
+	}
+}
+
+// WithProfilerOption sets the profiler recording the scopes of the
+// application and its renderer, and enables it.
+func WithProfilerOption(p *profiler.Profiler) Option {
+	return func(a *Application) error {
+		if p == nil {
+			return errors.New("profiler is nil")
+		}
+		p.SetEnabled(true)
+		a.profiler = p
+		return nil
+	}
+}
+
+// WithMainThreadBudgetOption sets the time allowed every frame to run
+// the functions queued by RunOnMainThread and Do.
+func WithMainThreadBudgetOption(budget time.Duration) Option {
+	return func(a *Application) error {
+		if budget <= 0 {
+			return errors.New("main thread budget must be positive")
+		}
+		a.mainThread.budget = budget
+		return nil
+	}
+}
+
+// WithAssetRootOption resolves relative asset paths against the directory dir
+// instead of the working directory.
+func WithAssetRootOption(dir string) Option {
+	return func(a *Application) error {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("asset root is not a directory: %s", dir)
+		}
+		a.assetRoot = os.DirFS(dir)
+		return nil
+	}
+}
+
+// WithAssetFSOption resolves relative asset paths against fsys.
+func WithAssetFSOption(fsys fs.FS) Option {
+	return func(a *Application) error {
+		if fsys == nil {
+			return errors.New("asset file system is nil")
+		}
+		a.assetRoot = fsys
+		return nil
+	}
+}
+
+// WithAssetPackOption resolves relative asset paths against the files of
+// the asset pack at path, built using tools/asset_pack.
+func WithAssetPackOption(path string) Option {
+	return func(a *Application) error {
+		p, err := pack.Open(path)
+		if err != nil {
+			return err
+		}
+		if a.assetPack != nil {
+			a.assetPack.Close()
+		}
+		a.assetPack = p
+		a.assetRoot = p
+		return nil
+	}
+}
+
+// WithHotReloadOption polls the texture files loaded through the asset
+// manager every interval, re-uploading the ones which changed in place.
+func WithHotReloadOption(interval time.Duration) Option {
+	return func(a *Application) error {
+		if interval <= 0 {
+			return errors.New("hot reload interval must be positive")
+		}
+		a.hotReloadInterval = interval
+		return nil
+	}
+}
+
+// WithInputConfigOption loads input contexts from the JSON file at path,
+// replacing the default contexts with the same names.
+func WithInputConfigOption(path string) Option {
+	return func(a *Application) error {
+		return a.input.LoadFile(path)
+	}
+}
+
+// WithInputRecordingOption records the input received by the window and
+// the delta time of every frame to the file at path, to be replayed using
+// WithInputReplayOption.
+func WithInputRecordingOption(path string) Option {
+	return func(a *Application) error {
+		if path == "" {
+			return errors.New("input recording path is empty")
+		}
+		a.recordPath = path
+		return nil
+	}
+}
+
+// WithInputReplayOption replays the input recording at path instead of
+// polling the input of the window, using the recorded delta times. The
+// application closes once every frame was replayed.
+func WithInputReplayOption(path string) Option {
+	return func(a *Application) error {
+		rec, err := replay.Load(path)
+		if err != nil {
+			return fmt.Errorf("error loading input recording: %s", err)
+		}
+		a.replay = replay.NewPlayer(rec)
+		return nil
+	}
+}
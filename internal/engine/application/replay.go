@@ -0,0 +1,80 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/devodev/opengl-experiment/internal/engine/replay"
+)
+
+// startRecording creates the recording file, and enables
+// recording the input of the window.
+func (a *Application) startRecording() error {
+	if a.recordPath == "" {
+		return nil
+	}
+	width, height := a.window.GetSize()
+	header := replay.Header{Width: width, Height: height}
+	if a.fixedStep != nil {
+		header.TickRate = a.fixedStep.TickRate()
+		header.MaxSteps = a.fixedStep.MaxSteps()
+	}
+	w, err := replay.Create(a.recordPath, header)
+	if err != nil {
+		return fmt.Errorf("error creating input recording: %s", err)
+	}
+	a.recorder = w
+	a.window.SetInputRecording(true)
+	a.logger.Infof("recording input to %s", a.recordPath)
+	return nil
+}
+
+// recordFrame writes the input polled during the frame. Recording
+// stops on error, so that a full disk does not stop the application.
+func (a *Application) recordFrame(dt float64) {
+	if a.recorder == nil {
+		return
+	}
+	f := a.window.RecordedInput()
+	if f == nil {
+		return
+	}
+	if err := a.recorder.WriteFrame(&replay.Frame{Delta: dt, Input: *f}); err != nil {
+		a.logger.Errorf("error recording input, recording stopped: %s", err)
+		a.stopRecording()
+	}
+}
+
+func (a *Application) stopRecording() {
+	if a.recorder == nil {
+		return
+	}
+	a.window.SetInputRecording(false)
+	if err := a.recorder.Close(); err != nil {
+		a.logger.Errorf("error closing input recording: %s", err)
+	}
+	a.logger.Infof("recorded %d frames of input to %s", a.recorder.Frames(), a.recordPath)
+	a.recorder = nil
+}
+
+// startReplay checks that the recording matches the current session,
+// as replaying it in a different one is not deterministic.
+func (a *Application) startReplay() {
+	if a.replay == nil {
+		return
+	}
+	rec := a.replay.Recording()
+	h := rec.Header
+	if width, height := a.window.GetSize(); width != h.Width || height != h.Height {
+		a.logger.Warnf("input recording made with a %dx%d window, replaying in a %dx%d window", h.Width, h.Height, width, height)
+	}
+	var tickRate float64
+	var maxSteps int
+	if a.fixedStep != nil {
+		tickRate, maxSteps = a.fixedStep.TickRate(), a.fixedStep.MaxSteps()
+	}
+	if tickRate != h.TickRate || maxSteps != h.MaxSteps {
+		a.logger.Warnf("input recording made with a fixed tick rate of %g (%d max steps), replaying with %g (%d max steps)",
+			h.TickRate, h.MaxSteps, tickRate, maxSteps)
+	}
+	a.logger.Infof("replaying %d frames (%.2fs) of input", len(rec.Frames), rec.Duration())
+}
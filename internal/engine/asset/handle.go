@@ -0,0 +1,177 @@
+package asset
+
+import (
+	"sync"
+
+	"github.com/devodev/opengl-experiment/internal/opengl"
+)
+
+// State of an asset handle.
+type State int
+
+// Handle states
+const (
+	StateLoading State = iota
+	StateReady
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateLoading:
+		return "loading"
+	case StateReady:
+		return "ready"
+	case StateFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// TextureHandle is a future of a texture being loaded. It implements
+// opengl.Texture and can be drawn right away: it delegates to the
+// placeholder texture until the texture is ready, or when loading failed.
+type TextureHandle struct {
+	name string
+
+	mu       sync.RWMutex
+	state    State
+	err      error
+	texture  opengl.Texture
+	fallback opengl.Texture
+
+	done chan struct{}
+	// release is set on handles owned by a Manager
+	release func()
+}
+
+func newTextureHandle(name string, placeholder opengl.Texture) *TextureHandle {
+	return &TextureHandle{
+		name:     name,
+		fallback: placeholder,
+		done:     make(chan struct{}),
+	}
+}
+
+// complete sets the result of the load. It can also be called on a handle
+// whose load failed, done being closed once.
+func (h *TextureHandle) complete(texture opengl.Texture, err error) {
+	h.mu.Lock()
+	loading := h.state == StateLoading
+	if err != nil {
+		h.state = StateFailed
+		h.err = err
+	} else {
+		h.state = StateReady
+		h.texture = texture
+	}
+	done := h.done
+	h.mu.Unlock()
+	if loading {
+		close(done)
+	}
+}
+
+// retry resets a handle whose load failed to the loading state, so that
+// it can be loaded again. It returns false when the load did not fail.
+func (h *TextureHandle) retry() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state != StateFailed {
+		return false
+	}
+	h.state = StateLoading
+	h.err = nil
+	h.done = make(chan struct{})
+	return true
+}
+
+// unload detaches the texture from the handle, which draws the
+// placeholder afterwards, and returns it.
+func (h *TextureHandle) unload() opengl.Texture {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	texture := h.texture
+	h.texture = nil
+	return texture
+}
+
+// Release drops a reference to a handle acquired from a Manager, every
+// call dropping one. The texture is unloaded once every reference is released.
+// It does nothing for handles created directly by a Loader.
+func (h *TextureHandle) Release() {
+	h.mu.RLock()
+	release := h.release
+	h.mu.RUnlock()
+	if release != nil {
+		release()
+	}
+}
+
+// Name .
+func (h *TextureHandle) Name() string {
+	return h.name
+}
+
+// State .
+func (h *TextureHandle) State() State {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.state
+}
+
+// Ready reports whether the texture was uploaded.
+func (h *TextureHandle) Ready() bool {
+	return h.State() == StateReady
+}
+
+// Err returns the error that made the load fail, if any.
+func (h *TextureHandle) Err() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.err
+}
+
+// Done returns a channel closed once the load completed.
+func (h *TextureHandle) Done() <-chan struct{} {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.done
+}
+
+// Wait blocks until the load completed and returns its error. It must not
+// be called from the main thread, which performs the upload.
+func (h *TextureHandle) Wait() error {
+	<-h.Done()
+	return h.Err()
+}
+
+// Texture returns the loaded texture, or the placeholder when not ready.
+func (h *TextureHandle) Texture() opengl.Texture {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.texture != nil {
+		return h.texture
+	}
+	return h.fallback
+}
+
+// Bind implements the Binder interface.
+func (h *TextureHandle) Bind() {
+	h.Texture().Bind()
+}
+
+// Unbind implements the Unbinder interface.
+func (h *TextureHandle) Unbind() {
+	h.Texture().Unbind()
+}
+
+// Index implements the opengl.Texture interface.
+func (h *TextureHandle) Index() int {
+	return h.Texture().Index()
+}
+
+// Unit implements the opengl.Texture interface.
+func (h *TextureHandle) Unit() uint32 {
+	return h.Texture().Unit()
+}
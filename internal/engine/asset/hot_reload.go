@@ -0,0 +1,173 @@
+package asset
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/devodev/opengl-experiment/internal/opengl"
+)
+
+// ReloadCallback is called after an asset was reloaded, or failed to.
+type ReloadCallback func(name string, err error)
+
+// textureWatch is a texture watched for changes on disk.
+type textureWatch struct {
+	name    string
+	handle  *TextureHandle
+	modTime time.Time
+}
+
+// stat returns the file info of the file at name,
+// resolved against the root when relative.
+func (m *Manager) stat(name string) (fs.FileInfo, error) {
+	if filepath.IsAbs(name) {
+		return os.Stat(name)
+	}
+	m.rootMu.RLock()
+	root := m.root
+	m.rootMu.RUnlock()
+	return fs.Stat(root, path.Clean(filepath.ToSlash(name)))
+}
+
+// watchTexture starts watching the texture file loaded by h.
+func (m *Manager) watchTexture(k, name string, h *TextureHandle) {
+	w := &textureWatch{name: name, handle: h}
+	if info, err := m.stat(name); err == nil {
+		w.modTime = info.ModTime()
+	}
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	m.watches[k] = w
+}
+
+func (m *Manager) unwatch(k string) {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	delete(m.watches, k)
+}
+
+// SetReloadCallback sets the callback called after an asset was reloaded.
+func (m *Manager) SetReloadCallback(fn ReloadCallback) {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	m.onReload = fn
+}
+
+// StartHotReload polls the modification time of the texture files every
+// interval, and re-uploads the ones which changed in place: their handles
+// are kept, so everything drawing them updates live.
+func (m *Manager) StartHotReload(interval time.Duration) {
+	m.StopHotReload()
+
+	stop := make(chan struct{})
+	m.watchMu.Lock()
+	m.stopHotReload = stop
+	m.watchMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.poll()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopHotReload stops polling the texture files.
+func (m *Manager) StopHotReload() {
+	m.watchMu.Lock()
+	defer m.watchMu.Unlock()
+	if m.stopHotReload != nil {
+		close(m.stopHotReload)
+		m.stopHotReload = nil
+	}
+}
+
+// poll reloads the watched textures whose file changed.
+func (m *Manager) poll() {
+	m.watchMu.Lock()
+	watches := make([]*textureWatch, 0, len(m.watches))
+	for _, w := range m.watches {
+		watches = append(watches, w)
+	}
+	m.watchMu.Unlock()
+
+	for _, w := range watches {
+		info, err := m.stat(w.name)
+		if err != nil {
+			// the file may be in the middle of being saved
+			continue
+		}
+		// wait for the first load to complete before reloading
+		if !info.ModTime().After(w.modTime) || w.handle.State() == StateLoading {
+			continue
+		}
+		w.modTime = info.ModTime()
+		m.reloadTexture(w)
+	}
+}
+
+func (m *Manager) reloadTexture(w *textureWatch) {
+	m.loader.reload(w.name, m.decodeTexture(w.name), func(rgba *image.NRGBA, err error) {
+		if err == nil {
+			err = w.handle.replace(rgba)
+		}
+		m.watchMu.Lock()
+		onReload := m.onReload
+		m.watchMu.Unlock()
+		if onReload != nil {
+			onReload(w.name, err)
+		}
+	})
+}
+
+// decodeTexture returns a function decoding the texture file at name.
+func (m *Manager) decodeTexture(name string) func() (*image.NRGBA, error) {
+	return func() (*image.NRGBA, error) {
+		f, err := m.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return opengl.DecodeNRGBA(f)
+	}
+}
+
+// replace uploads rgba into the texture of the handle, or creates it
+// when the first load failed, the file missing or being written at the
+// time. It must be called on the main thread.
+func (h *TextureHandle) replace(rgba *image.NRGBA) error {
+	h.mu.RLock()
+	texture, state := h.texture, h.state
+	h.mu.RUnlock()
+
+	if texture == nil {
+		// a load in flight completes the handle
+		if state == StateLoading {
+			return nil
+		}
+		created, err := opengl.NewNRGBATextureFromImage(rgba)
+		if err != nil {
+			return fmt.Errorf("error reloading texture %s: %s", h.name, err)
+		}
+		h.complete(created, nil)
+		return nil
+	}
+
+	mutable, ok := texture.(opengl.MutableTexture)
+	if !ok {
+		return fmt.Errorf("error reloading texture %s: texture cannot be updated", h.name)
+	}
+	mutable.SetNRGBA(rgba)
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package asset loads assets in the background: files are decoded on
+// worker goroutines and uploaded to the GPU on the main thread.
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/devodev/opengl-experiment/internal/opengl"
+)
+
+// Errors
+var (
+	ErrLoaderClosed = errors.New("asset loader closed")
+)
+
+var (
+	defaultWorkers = runtime.NumCPU()
+
+	placeholderSize   = 8
+	placeholderColors = [2][4]uint8{
+		{255, 0, 255, 255},
+		{0, 0, 0, 255},
+	}
+)
+
+// Scheduler runs functions on the main thread, where GL calls are allowed.
+// It is implemented by application.Application.
+type Scheduler interface {
+	RunOnMainThread(func())
+}
+
+// Progress reports the state of the requested loads.
+type Progress struct {
+	Total  int
+	Loaded int
+	Failed int
+}
+
+// Done reports whether every requested load completed.
+func (p Progress) Done() bool {
+	return p.Loaded+p.Failed >= p.Total
+}
+
+// Fraction returns the completed fraction of the requested loads, in [0, 1].
+func (p Progress) Fraction() float64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float64(p.Loaded+p.Failed) / float64(p.Total)
+}
+
+// Loader decodes assets on worker goroutines and schedules
+// their upload on the main thread.
+type Loader struct {
+	scheduler   Scheduler
+	placeholder opengl.Texture
+	workerCount int
+
+	// pending holds the queued jobs, unbounded so that
+	// requesting a load never blocks the caller
+	mu      sync.Mutex
+	cond    *sync.Cond
+	closed  bool
+	pending []func()
+	workers sync.WaitGroup
+	// uploads holds the handles whose upload is queued on the main
+	// thread, as the queue may not be drained once the loader is closed
+	uploads map[*TextureHandle]struct{}
+
+	total  int64
+	loaded int64
+	failed int64
+}
+
+// LoaderOption .
+type LoaderOption func(*Loader) error
+
+// WithWorkersOption sets the number of decoding goroutines.
+func WithWorkersOption(workers int) LoaderOption {
+	return func(l *Loader) error {
+		if workers <= 0 {
+			return errors.New("workers must be positive")
+		}
+		l.workerCount = workers
+		return nil
+	}
+}
+
+// NewLoader creates a loader and starts its workers. It must be called
+// on the main thread as it creates the placeholder texture.
+func NewLoader(scheduler Scheduler, options ...LoaderOption) (*Loader, error) {
+	l := &Loader{
+		scheduler:   scheduler,
+		workerCount: defaultWorkers,
+		uploads:     make(map[*TextureHandle]struct{}),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	for _, opt := range options {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+
+	placeholder, err := opengl.NewNRGBATextureFromImage(newPlaceholderImage())
+	if err != nil {
+		return nil, fmt.Errorf("error creating placeholder texture: %s", err)
+	}
+	l.placeholder = placeholder
+
+	l.workers.Add(l.workerCount)
+	for i := 0; i < l.workerCount; i++ {
+		go l.work()
+	}
+	return l, nil
+}
+
+func (l *Loader) work() {
+	defer l.workers.Done()
+	for {
+		l.mu.Lock()
+		for len(l.pending) == 0 && !l.closed {
+			l.cond.Wait()
+		}
+		if len(l.pending) == 0 {
+			l.mu.Unlock()
+			return
+		}
+		job := l.pending[0]
+		l.pending[0] = nil
+		l.pending = l.pending[1:]
+		l.mu.Unlock()
+
+		job()
+	}
+}
+
+// enqueue queues job for the workers, returning false once closed.
+func (l *Loader) enqueue(job func()) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.pending = append(l.pending, job)
+	l.cond.Signal()
+	return true
+}
+
+// Placeholder returns the texture used by handles until they are ready.
+func (l *Loader) Placeholder() opengl.Texture {
+	return l.placeholder
+}
+
+// LoadTexture starts loading the image file at path and returns a handle
+// usable immediately, which draws the placeholder until the texture is
+// uploaded. It is safe to call from any goroutine.
+func (l *Loader) LoadTexture(path string) *TextureHandle {
+	return l.LoadTextureFunc(path, func() (*image.NRGBA, error) {
+		return opengl.LoadNRGBA(path)
+	})
+}
+
+// LoadTextureFunc is like LoadTexture, but decodes the image using decode,
+// which runs on a worker goroutine.
+func (l *Loader) LoadTextureFunc(name string, decode func() (*image.NRGBA, error)) *TextureHandle {
+	h := newTextureHandle(name, l.placeholder)
+	l.load(h, name, decode)
+	return h
+}
+
+// load starts loading the texture of h, which must be loading.
+func (l *Loader) load(h *TextureHandle, name string, decode func() (*image.NRGBA, error)) {
+	atomic.AddInt64(&l.total, 1)
+	queued := l.enqueue(func() {
+		rgba, err := decode()
+		if err != nil {
+			l.fail(h, fmt.Errorf("error loading texture %s: %s", name, err))
+			return
+		}
+		l.mu.Lock()
+		l.uploads[h] = struct{}{}
+		l.mu.Unlock()
+		l.scheduler.RunOnMainThread(func() {
+			if !l.takeUpload(h) {
+				return
+			}
+			texture, err := opengl.NewNRGBATextureFromImage(rgba)
+			if err != nil {
+				l.fail(h, fmt.Errorf("error uploading texture %s: %s", name, err))
+				return
+			}
+			atomic.AddInt64(&l.loaded, 1)
+			h.complete(texture, nil)
+		})
+	})
+	if !queued {
+		atomic.AddInt64(&l.total, -1)
+		h.complete(nil, ErrLoaderClosed)
+	}
+}
+
+// reload decodes an image on a worker goroutine, then
+// calls done with the result on the main thread.
+func (l *Loader) reload(name string, decode func() (*image.NRGBA, error), done func(*image.NRGBA, error)) {
+	l.enqueue(func() {
+		rgba, err := decode()
+		if err != nil {
+			err = fmt.Errorf("error loading texture %s: %s", name, err)
+		}
+		l.scheduler.RunOnMainThread(func() {
+			done(rgba, err)
+		})
+	})
+}
+
+// takeUpload removes h from the queued uploads, returning false
+// when it was already completed by Close.
+func (l *Loader) takeUpload(h *TextureHandle) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.uploads[h]
+	delete(l.uploads, h)
+	return ok
+}
+
+func (l *Loader) fail(h *TextureHandle, err error) {
+	atomic.AddInt64(&l.failed, 1)
+	h.complete(nil, err)
+}
+
+// Progress returns the state of the loads requested so far.
+func (l *Loader) Progress() Progress {
+	return Progress{
+		Total:  int(atomic.LoadInt64(&l.total)),
+		Loaded: int(atomic.LoadInt64(&l.loaded)),
+		Failed: int(atomic.LoadInt64(&l.failed)),
+	}
+}
+
+// Close stops the workers once the queued files are decoded. The handles
+// whose upload is still queued on the main thread are completed with
+// ErrLoaderClosed, so that goroutines waiting on them return.
+func (l *Loader) Close() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.closed = true
+	l.cond.Broadcast()
+	l.mu.Unlock()
+
+	l.workers.Wait()
+
+	l.mu.Lock()
+	uploads := l.uploads
+	l.uploads = nil
+	l.mu.Unlock()
+	for h := range uploads {
+		l.fail(h, ErrLoaderClosed)
+	}
+}
+
+// newPlaceholderImage creates a checkerboard, easy to spot when an asset is missing.
+func newPlaceholderImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, placeholderSize, placeholderSize))
+	for y := 0; y < placeholderSize; y++ {
+		for x := 0; x < placeholderSize; x++ {
+			c := placeholderColors[(x/(placeholderSize/2)+y/(placeholderSize/2))%2]
+			idx := img.PixOffset(x, y)
+			copy(img.Pix[idx:idx+4], c[:])
+		}
+	}
+	return img
+}
@@ -0,0 +1,294 @@
+package asset
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/devodev/opengl-experiment/internal/opengl"
+)
+
+// ShaderHandle is a shader program shared through a Manager.
+type ShaderHandle struct {
+	*opengl.ShaderProgram
+	release func()
+}
+
+// Release drops a reference to the shader program.
+// It is deleted once every reference is released.
+func (h *ShaderHandle) Release() {
+	h.release()
+}
+
+// FontHandle is a font shared through a Manager.
+type FontHandle struct {
+	*renderer.Font
+	release func()
+}
+
+// Release drops a reference to the font.
+// It is deleted once every reference is released.
+func (h *FontHandle) Release() {
+	h.release()
+}
+
+type managedAsset struct {
+	refs int
+	// ready is closed once the asset is loaded, err being set on failure
+	ready  chan struct{}
+	err    error
+	asset  interface{}
+	unload func()
+}
+
+// Manager caches the assets loaded from its root, keyed by kind and path.
+// Loading an asset already cached returns the same asset and increments
+// its reference count; it is unloaded once every reference is released.
+type Manager struct {
+	loader *Loader
+
+	rootMu sync.RWMutex
+	root   fs.FS
+
+	mu     sync.Mutex
+	assets map[string]*managedAsset
+
+	// textures watched for hot reloading
+	watchMu       sync.Mutex
+	watches       map[string]*textureWatch
+	onReload      ReloadCallback
+	stopHotReload chan struct{}
+}
+
+// NewManager creates a manager loading textures through loader and
+// resolving relative paths against root. Absolute paths are read
+// from the file system directly.
+func NewManager(loader *Loader, root fs.FS) *Manager {
+	return &Manager{
+		loader:  loader,
+		root:    root,
+		assets:  make(map[string]*managedAsset),
+		watches: make(map[string]*textureWatch),
+	}
+}
+
+// SetRoot sets the file system against which relative paths are resolved.
+// Assets already cached are not affected.
+func (m *Manager) SetRoot(root fs.FS) {
+	m.rootMu.Lock()
+	defer m.rootMu.Unlock()
+	m.root = root
+}
+
+// Open opens the file at name, resolved against the root when relative.
+func (m *Manager) Open(name string) (io.ReadCloser, error) {
+	if filepath.IsAbs(name) {
+		return os.Open(name)
+	}
+	m.rootMu.RLock()
+	root := m.root
+	m.rootMu.RUnlock()
+	return root.Open(path.Clean(filepath.ToSlash(name)))
+}
+
+// ReadFile reads the file at name, resolved against the root when relative.
+func (m *Manager) ReadFile(name string) ([]byte, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// key identifies an asset of kind loaded from names.
+func key(kind string, names ...string) string {
+	k := kind
+	for _, name := range names {
+		if !filepath.IsAbs(name) {
+			name = path.Clean(filepath.ToSlash(name))
+		}
+		k += ":" + name
+	}
+	return k
+}
+
+// acquire returns the cached asset at k with a new reference, or creates
+// it using load. The lock is not held while loading, so that assets load
+// concurrently; callers acquiring an asset being loaded wait for it.
+func (m *Manager) acquire(k string, load func() (interface{}, func(), error)) (interface{}, func(), error) {
+	m.mu.Lock()
+	a, ok := m.assets[k]
+	if !ok {
+		a = &managedAsset{ready: make(chan struct{})}
+		m.assets[k] = a
+	}
+	a.refs++
+	m.mu.Unlock()
+
+	if ok {
+		<-a.ready
+	} else {
+		a.asset, a.unload, a.err = load()
+		if a.err != nil {
+			m.mu.Lock()
+			if m.assets[k] == a {
+				delete(m.assets, k)
+			}
+			m.mu.Unlock()
+		}
+		close(a.ready)
+	}
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+
+	release := func() { m.release(k, a) }
+	return a.asset, release, nil
+}
+
+// release drops a reference to a, unloading it when none is left.
+func (m *Manager) release(k string, a *managedAsset) {
+	m.mu.Lock()
+	if a.refs <= 0 {
+		m.mu.Unlock()
+		return
+	}
+	a.refs--
+	unload := a.refs == 0
+	if unload && m.assets[k] == a {
+		delete(m.assets, k)
+	}
+	m.mu.Unlock()
+
+	if unload {
+		a.unload()
+	}
+}
+
+// Texture returns the texture at name, starting to load it in the
+// background when it is not cached, or when its last load failed. The
+// returned handle draws a placeholder until ready, and must be released
+// when not used anymore.
+func (m *Manager) Texture(name string) *TextureHandle {
+	k := key("texture", name)
+	asset, release, _ := m.acquire(k, func() (interface{}, func(), error) {
+		h := m.loader.LoadTextureFunc(name, m.decodeTexture(name))
+		m.watchTexture(k, name, h)
+		unload := func() {
+			m.unwatch(k)
+			// wait for the upload before deleting the texture
+			go func() {
+				<-h.Done()
+				m.loader.scheduler.RunOnMainThread(func() {
+					if d, ok := h.unload().(opengl.Deleter); ok {
+						d.Delete()
+					}
+				})
+			}()
+		}
+		return h, unload, nil
+	})
+	h := asset.(*TextureHandle)
+
+	// a cached texture which failed to load, the file missing for
+	// example, is loaded again in place, keeping its handle
+	if h.retry() {
+		m.loader.load(h, name, m.decodeTexture(name))
+	}
+
+	// every reference shares the same handle,
+	// each call to Release drops one reference
+	h.mu.Lock()
+	h.release = release
+	h.mu.Unlock()
+	return h
+}
+
+// Shader returns the shader program compiled from the vertex and
+// fragment shader files. It must be called on the main thread.
+func (m *Manager) Shader(vertexPath, fragmentPath string) (*ShaderHandle, error) {
+	asset, release, err := m.acquire(key("shader", vertexPath, fragmentPath), func() (interface{}, func(), error) {
+		vertexSource, err := m.ReadFile(vertexPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading vertex shader: %s", err)
+		}
+		fragmentSource, err := m.ReadFile(fragmentPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading fragment shader: %s", err)
+		}
+		program, err := opengl.NewShaderProgram(
+			string(append(vertexSource, byte('\x00'))),
+			string(append(fragmentSource, byte('\x00'))),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating shader program: %s", err)
+		}
+		unload := func() {
+			m.loader.scheduler.RunOnMainThread(program.Delete)
+		}
+		return program, unload, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ShaderHandle{ShaderProgram: asset.(*opengl.ShaderProgram), release: onlyOnce(release)}, nil
+}
+
+// Font returns the font at name rasterized at pixelSize.
+// It must be called on the main thread.
+func (m *Manager) Font(name string, pixelSize float32) (*FontHandle, error) {
+	return m.font(name, pixelSize, false)
+}
+
+// SDFFont returns the SDF font at name rasterized at pixelSize.
+// It must be called on the main thread.
+func (m *Manager) SDFFont(name string, pixelSize float32) (*FontHandle, error) {
+	return m.font(name, pixelSize, true)
+}
+
+func (m *Manager) font(name string, pixelSize float32, sdf bool) (*FontHandle, error) {
+	kind := fmt.Sprintf("font-%g", pixelSize)
+	if sdf {
+		kind = fmt.Sprintf("sdffont-%g", pixelSize)
+	}
+	asset, release, err := m.acquire(key(kind, name), func() (interface{}, func(), error) {
+		ttf, err := m.ReadFile(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading font file: %s", err)
+		}
+		newFont := renderer.NewFont
+		if sdf {
+			newFont = renderer.NewSDFFont
+		}
+		font, err := newFont(ttf, pixelSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		unload := func() {
+			m.loader.scheduler.RunOnMainThread(font.Delete)
+		}
+		return font, unload, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &FontHandle{Font: asset.(*renderer.Font), release: onlyOnce(release)}, nil
+}
+
+func onlyOnce(fn func()) func() {
+	var once sync.Once
+	return func() { once.Do(fn) }
+}
+
+// Len returns the number of cached assets.
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.assets)
+}
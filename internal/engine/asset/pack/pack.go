@@ -0,0 +1,313 @@
+// Package pack implements a packed archive format bundling asset files,
+// and an fs.FS reading it.
+//
+// A pack starts with a header holding its magic and version, followed by
+// the file blobs, compressed using DEFLATE when it makes them smaller, and
+// ends with the index of the files and a trailer locating it:
+//
+//	header  magic [4]byte "GLPK", version uint16
+//	blobs   file data
+//	index   count uint32, count * entry
+//	trailer index offset uint64, index size uint64, index crc32 uint32, magic [4]byte
+//
+// Each index entry holds the file name, its modification time, the offset
+// and size of its blob, its size once decompressed and the CRC-32 of its
+// data. Integers are little endian.
+package pack
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// Version is the version of the format written.
+const Version uint16 = 1
+
+// Errors
+var (
+	ErrInvalidPack        = errors.New("invalid asset pack")
+	ErrUnsupportedVersion = errors.New("unsupported asset pack version")
+	ErrChecksumMismatch   = errors.New("asset pack checksum mismatch")
+	ErrWriterClosed       = errors.New("asset pack writer closed")
+)
+
+var (
+	magic = [4]byte{'G', 'L', 'P', 'K'}
+
+	headerSize  = 4 + 2
+	trailerSize = 8 + 8 + 4 + 4
+
+	byteOrder = binary.LittleEndian
+)
+
+// Compression of a file blob.
+type Compression uint8
+
+// Compression methods
+const (
+	CompressionNone Compression = iota
+	CompressionDeflate
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionDeflate:
+		return "deflate"
+	}
+	return "unknown"
+}
+
+// Entry describes a file stored in a pack.
+type Entry struct {
+	Name        string
+	ModTime     time.Time
+	Compression Compression
+	// Offset and CompressedSize locate the blob in the pack
+	Offset         int64
+	CompressedSize int64
+	// Size of the data once decompressed
+	Size int64
+	// CRC32 is the IEEE checksum of the data once decompressed
+	CRC32 uint32
+}
+
+// Writer writes a pack to an underlying writer.
+// Close must be called to write the index.
+type Writer struct {
+	w      io.Writer
+	offset int64
+	names  map[string]bool
+	index  []Entry
+	closed bool
+	err    error
+}
+
+// NewWriter creates a writer and writes the pack header to w.
+func NewWriter(w io.Writer) (*Writer, error) {
+	pw := &Writer{w: w, names: make(map[string]bool)}
+	header := make([]byte, headerSize)
+	copy(header, magic[:])
+	byteOrder.PutUint16(header[4:], Version)
+	if err := pw.write(header); err != nil {
+		return nil, fmt.Errorf("error writing header: %s", err)
+	}
+	return pw, nil
+}
+
+func (pw *Writer) write(p []byte) error {
+	if pw.err != nil {
+		return pw.err
+	}
+	n, err := pw.w.Write(p)
+	pw.offset += int64(n)
+	pw.err = err
+	return err
+}
+
+// Add adds a file to the pack. Its name must be a valid fs.FS path
+// and unique within the pack.
+func (pw *Writer) Add(name string, modTime time.Time, data []byte) error {
+	if pw.closed {
+		return ErrWriterClosed
+	}
+	if !fs.ValidPath(name) || name == "." {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	if pw.names[name] {
+		return fmt.Errorf("duplicate file name %q", name)
+	}
+
+	entry := Entry{
+		Name:        name,
+		ModTime:     modTime,
+		Compression: CompressionNone,
+		Offset:      pw.offset,
+		Size:        int64(len(data)),
+		CRC32:       crc32.ChecksumIEEE(data),
+	}
+	blob := data
+	compressed, err := deflate(data)
+	if err != nil {
+		return fmt.Errorf("error compressing %s: %s", name, err)
+	}
+	// already compressed files, such as png images, are stored as is
+	if len(compressed) < len(data) {
+		entry.Compression = CompressionDeflate
+		blob = compressed
+	}
+	entry.CompressedSize = int64(len(blob))
+
+	if err := pw.write(blob); err != nil {
+		return fmt.Errorf("error writing %s: %s", name, err)
+	}
+	pw.names[name] = true
+	pw.index = append(pw.index, entry)
+	return nil
+}
+
+// Close writes the index and the trailer. It does not close the underlying writer.
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return ErrWriterClosed
+	}
+	pw.closed = true
+
+	sort.Slice(pw.index, func(i, j int) bool { return pw.index[i].Name < pw.index[j].Name })
+	index := encodeIndex(pw.index)
+	indexOffset := pw.offset
+	if err := pw.write(index); err != nil {
+		return fmt.Errorf("error writing index: %s", err)
+	}
+
+	trailer := make([]byte, trailerSize)
+	byteOrder.PutUint64(trailer[0:], uint64(indexOffset))
+	byteOrder.PutUint64(trailer[8:], uint64(len(index)))
+	byteOrder.PutUint32(trailer[16:], crc32.ChecksumIEEE(index))
+	copy(trailer[20:], magic[:])
+	if err := pw.write(trailer); err != nil {
+		return fmt.Errorf("error writing trailer: %s", err)
+	}
+	return nil
+}
+
+// Entries returns the files added so far.
+func (pw *Writer) Entries() []Entry {
+	return append([]Entry(nil), pw.index...)
+}
+
+// WriteFS writes every regular file of fsys to a pack written to w.
+func WriteFS(w io.Writer, fsys fs.FS) ([]Entry, error) {
+	pw, err := NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return pw.Add(name, info.ModTime(), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := pw.Close(); err != nil {
+		return nil, err
+	}
+	return pw.Entries(), nil
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeIndex(entries []Entry) []byte {
+	var buf bytes.Buffer
+	scratch := make([]byte, 8)
+	putUint := func(v uint64, size int) {
+		switch size {
+		case 1:
+			scratch[0] = byte(v)
+		case 2:
+			byteOrder.PutUint16(scratch, uint16(v))
+		case 4:
+			byteOrder.PutUint32(scratch, uint32(v))
+		case 8:
+			byteOrder.PutUint64(scratch, v)
+		}
+		buf.Write(scratch[:size])
+	}
+
+	putUint(uint64(len(entries)), 4)
+	for _, e := range entries {
+		putUint(uint64(len(e.Name)), 2)
+		buf.WriteString(e.Name)
+		putUint(uint64(e.ModTime.UnixNano()), 8)
+		putUint(uint64(e.Compression), 1)
+		putUint(uint64(e.Offset), 8)
+		putUint(uint64(e.CompressedSize), 8)
+		putUint(uint64(e.Size), 8)
+		putUint(uint64(e.CRC32), 4)
+	}
+	return buf.Bytes()
+}
+
+func decodeIndex(index []byte) ([]Entry, error) {
+	r := bytes.NewReader(index)
+	var count uint32
+	if err := binary.Read(r, byteOrder, &count); err != nil {
+		return nil, ErrInvalidPack
+	}
+	// each entry takes at least 39 bytes, guarding against huge allocations
+	if int64(count)*39 > int64(len(index)) {
+		return nil, ErrInvalidPack
+	}
+
+	entries := make([]Entry, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var nameLen uint16
+		if err := binary.Read(r, byteOrder, &nameLen); err != nil {
+			return nil, ErrInvalidPack
+		}
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, ErrInvalidPack
+		}
+		var fields struct {
+			ModTime        int64
+			Compression    uint8
+			Offset         int64
+			CompressedSize int64
+			Size           int64
+			CRC32          uint32
+		}
+		if err := binary.Read(r, byteOrder, &fields); err != nil {
+			return nil, ErrInvalidPack
+		}
+		if !fs.ValidPath(string(name)) || fields.Offset < 0 || fields.CompressedSize < 0 || fields.Size < 0 {
+			return nil, ErrInvalidPack
+		}
+		entries = append(entries, Entry{
+			Name:           path.Clean(string(name)),
+			ModTime:        time.Unix(0, fields.ModTime),
+			Compression:    Compression(fields.Compression),
+			Offset:         fields.Offset,
+			CompressedSize: fields.CompressedSize,
+			Size:           fields.Size,
+			CRC32:          fields.CRC32,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,304 @@
+package pack
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"time"
+)
+
+// Pack reads the files of a pack. It implements fs.FS, fs.ReadFileFS,
+// fs.ReadDirFS and fs.StatFS so it can be used as the root of an asset
+// manager in place of a directory. Directories are derived from the file
+// names. The data of a file is verified against its checksum when read.
+type Pack struct {
+	r       io.ReaderAt
+	closer  io.Closer
+	version uint16
+
+	files map[string]*Entry
+	// dirs holds the sorted entries of each directory
+	dirs map[string][]fs.DirEntry
+}
+
+// Open opens the pack file at name.
+func Open(name string) (*Pack, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p, err := NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading asset pack %s: %s", name, err)
+	}
+	p.closer = f
+	return p, nil
+}
+
+// NewReader reads the index of the pack of the given size read from r.
+func NewReader(r io.ReaderAt, size int64) (*Pack, error) {
+	if size < int64(headerSize+trailerSize) {
+		return nil, ErrInvalidPack
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], magic[:]) {
+		return nil, ErrInvalidPack
+	}
+	version := byteOrder.Uint16(header[4:])
+	if version == 0 || version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	trailer := make([]byte, trailerSize)
+	if _, err := r.ReadAt(trailer, size-int64(trailerSize)); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(trailer[20:], magic[:]) {
+		return nil, ErrInvalidPack
+	}
+	indexOffset := int64(byteOrder.Uint64(trailer[0:]))
+	indexSize := int64(byteOrder.Uint64(trailer[8:]))
+	if indexOffset < int64(headerSize) || indexSize < 0 || indexOffset+indexSize != size-int64(trailerSize) {
+		return nil, ErrInvalidPack
+	}
+
+	index := make([]byte, indexSize)
+	if _, err := r.ReadAt(index, indexOffset); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(index) != byteOrder.Uint32(trailer[16:]) {
+		return nil, fmt.Errorf("%w: index", ErrChecksumMismatch)
+	}
+	entries, err := decodeIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pack{
+		r:       r,
+		version: version,
+		files:   make(map[string]*Entry, len(entries)),
+		dirs:    map[string][]fs.DirEntry{".": nil},
+	}
+	for i := range entries {
+		e := &entries[i]
+		// sizes come from the file, check them before they are used to
+		// read, an uncompressed blob being stored as is
+		if e.Offset < int64(headerSize) || e.CompressedSize < 0 || e.Size < 0 ||
+			e.CompressedSize > indexOffset-e.Offset {
+			return nil, ErrInvalidPack
+		}
+		if e.Compression == CompressionNone && e.Size != e.CompressedSize {
+			return nil, ErrInvalidPack
+		}
+		if _, ok := p.files[e.Name]; ok {
+			return nil, ErrInvalidPack
+		}
+		p.files[e.Name] = e
+		p.addDirEntry(e.Name, &fileInfo{name: path.Base(e.Name), size: e.Size, modTime: e.ModTime})
+	}
+	for _, dirEntries := range p.dirs {
+		sort.Slice(dirEntries, func(i, j int) bool { return dirEntries[i].Name() < dirEntries[j].Name() })
+	}
+	return p, nil
+}
+
+// addDirEntry adds the entry at name to its parent directory,
+// creating the parent directories as needed.
+func (p *Pack) addDirEntry(name string, info *fileInfo) {
+	dir := path.Dir(name)
+	_, exists := p.dirs[dir]
+	p.dirs[dir] = append(p.dirs[dir], fs.FileInfoToDirEntry(info))
+	if !exists && dir != "." {
+		p.addDirEntry(dir, &fileInfo{name: path.Base(dir), mode: fs.ModeDir | 0555})
+	}
+}
+
+// Close closes the pack file, when opened using Open.
+func (p *Pack) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// Version returns the format version of the pack.
+func (p *Pack) Version() uint16 {
+	return p.version
+}
+
+// Entries returns the files of the pack, sorted by name.
+func (p *Pack) Entries() []Entry {
+	entries := make([]Entry, 0, len(p.files))
+	for _, e := range p.files {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Verify reads every file of the pack, checking its checksum.
+func (p *Pack) Verify() error {
+	for _, e := range p.Entries() {
+		if _, err := p.read(&e); err != nil {
+			return fmt.Errorf("error verifying %s: %s", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// read returns the decompressed data of e, verifying its checksum.
+func (p *Pack) read(e *Entry) ([]byte, error) {
+	var r io.Reader = io.NewSectionReader(p.r, e.Offset, e.CompressedSize)
+	switch e.Compression {
+	case CompressionNone:
+	case CompressionDeflate:
+		fr := flate.NewReader(r)
+		defer fr.Close()
+		r = fr
+	default:
+		return nil, fmt.Errorf("unsupported compression %d", e.Compression)
+	}
+
+	// read at most one byte past the declared size rather than allocating
+	// it upfront, so that a corrupted size fails on the data actually read
+	data, err := io.ReadAll(io.LimitReader(r, e.Size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != e.Size {
+		return nil, fmt.Errorf("%w: size mismatch", ErrInvalidPack)
+	}
+	if crc32.ChecksumIEEE(data) != e.CRC32 {
+		return nil, ErrChecksumMismatch
+	}
+	return data, nil
+}
+
+// Open implements the fs.FS interface.
+func (p *Pack) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if e, ok := p.files[name]; ok {
+		data, err := p.read(e)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &file{
+			Reader: bytes.NewReader(data),
+			info:   &fileInfo{name: path.Base(name), size: e.Size, modTime: e.ModTime},
+		}, nil
+	}
+	if entries, ok := p.dirs[name]; ok {
+		return &dir{
+			info:    &fileInfo{name: path.Base(name), mode: fs.ModeDir | 0555},
+			entries: entries,
+		}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile implements the fs.ReadFileFS interface.
+func (p *Pack) ReadFile(name string) ([]byte, error) {
+	e, ok := p.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	data, err := p.read(e)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// Stat implements the fs.StatFS interface, without reading the file data.
+func (p *Pack) Stat(name string) (fs.FileInfo, error) {
+	if e, ok := p.files[name]; ok {
+		return &fileInfo{name: path.Base(name), size: e.Size, modTime: e.ModTime}, nil
+	}
+	if _, ok := p.dirs[name]; ok {
+		return &fileInfo{name: path.Base(name), mode: fs.ModeDir | 0555}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir implements the fs.ReadDirFS interface.
+func (p *Pack) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, ok := p.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// fileInfo implements the fs.FileInfo interface.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) Mode() fs.FileMode  { return i.mode | 0444 }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *fileInfo) Sys() interface{}   { return nil }
+
+// file is a file of a pack, fully read and verified when opened.
+type file struct {
+	*bytes.Reader
+	info *fileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+// dir is a directory of a pack.
+type dir struct {
+	info    *fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements the fs.ReadDirFile interface.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry(nil), remaining...), nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return append([]fs.DirEntry(nil), remaining[:n]...), nil
+}
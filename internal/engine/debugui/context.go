@@ -0,0 +1,226 @@
+// Package debugui is a minimal immediate-mode GUI for the debug panels
+// of the engine. It is not Dear ImGui, only its API style is followed:
+// widgets are declared every frame between NewFrame and Render, and
+// drawn by a Renderer built on the internal/opengl primitives.
+package debugui
+
+import (
+	"hash/fnv"
+
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	defaultFontSize    = float32(15)
+	defaultWindowWidth = float32(280)
+	defaultWindowPos   = mgl32.Vec2{10, 10}
+	defaultPadding     = float32(6)
+	defaultItemSpacing = float32(4)
+
+	colorWindowBg     = mgl32.Vec4{0.06, 0.06, 0.06, 0.9}
+	colorTitleBg      = mgl32.Vec4{0.16, 0.29, 0.48, 1}
+	colorBorder       = mgl32.Vec4{0.43, 0.43, 0.5, 0.5}
+	colorText         = mgl32.Vec4{1, 1, 1, 1}
+	colorTextDisabled = mgl32.Vec4{0.5, 0.5, 0.5, 1}
+	colorFrameBg      = mgl32.Vec4{0.16, 0.29, 0.48, 0.54}
+	colorFrameHovered = mgl32.Vec4{0.26, 0.59, 0.98, 0.4}
+	colorFrameActive  = mgl32.Vec4{0.26, 0.59, 0.98, 0.67}
+	colorCheckMark    = mgl32.Vec4{0.26, 0.59, 0.98, 1}
+	colorPlotLines    = mgl32.Vec4{0.61, 0.61, 0.61, 1}
+)
+
+// ID identifies a widget across frames.
+type ID uint32
+
+// panel is a panel declared using Begin and End.
+type panel struct {
+	id        ID
+	title     string
+	pos       mgl32.Vec2
+	size      mgl32.Vec2
+	collapsed bool
+	// cursor is the position of the next widget
+	cursor mgl32.Vec2
+	// lastItemEnd is the position after the last widget on its line
+	lastItemEnd mgl32.Vec2
+	sameLine    bool
+	// touched is true when the window was declared this frame
+	touched bool
+
+	drawList DrawList
+}
+
+func (w *panel) rect() Rect {
+	return Rect{Min: w.pos, Max: w.pos.Add(w.size)}
+}
+
+// Context holds the GUI state and the input forwarded from the window.
+type Context struct {
+	font     *renderer.Font
+	fontSize float32
+
+	displaySize mgl32.Vec2
+	deltaTime   float64
+
+	// input state accumulated by the input callbacks
+	mousePos        mgl32.Vec2
+	mouseDown       [3]bool
+	mousePressedCB  [3]bool
+	mouseReleasedCB [3]bool
+	mouseWheel      float32
+
+	// input state of the current frame
+	mouseClicked  [3]bool
+	mouseReleased [3]bool
+	mouseDelta    mgl32.Vec2
+	lastMousePos  mgl32.Vec2
+	frameWheel    float32
+
+	hotID    ID
+	activeID ID
+
+	windows       map[ID]*panel
+	windowOrder   []*panel
+	currentWindow *panel
+	nextWindowPos mgl32.Vec2
+	hoveredWindow *panel
+}
+
+// NewContext creates a GUI context drawing text with font.
+// The font must not be an SDF font.
+func NewContext(font *renderer.Font) *Context {
+	return &Context{
+		font:          font,
+		fontSize:      defaultFontSize,
+		windows:       make(map[ID]*panel),
+		nextWindowPos: defaultWindowPos,
+	}
+}
+
+// OnMouseButton forwards a mouse button action.
+func (c *Context) OnMouseButton(button window.MouseButton, action window.KeyAction) {
+	if int(button) >= len(c.mouseDown) {
+		return
+	}
+	switch action {
+	case window.KeyActionPress:
+		c.mouseDown[button] = true
+		c.mousePressedCB[button] = true
+	case window.KeyActionRelease:
+		c.mouseDown[button] = false
+		c.mouseReleasedCB[button] = true
+	}
+}
+
+// OnCursorPos forwards the cursor position.
+func (c *Context) OnCursorPos(x, y float64) {
+	c.mousePos = mgl32.Vec2{float32(x), float32(y)}
+}
+
+// OnScroll forwards a scroll offset.
+func (c *Context) OnScroll(xOffset, yOffset float64) {
+	c.mouseWheel += float32(yOffset)
+}
+
+// OnChar forwards a typed character.
+func (c *Context) OnChar(char rune) {}
+
+// WantCaptureMouse reports whether the mouse is used by the GUI,
+// in which case the application should not process it.
+func (c *Context) WantCaptureMouse() bool {
+	return c.hoveredWindow != nil || c.activeID != 0
+}
+
+// DisplaySize returns the size of the display set by NewFrame.
+func (c *Context) DisplaySize() mgl32.Vec2 {
+	return c.displaySize
+}
+
+// DeltaTime returns the frame delta time set by NewFrame.
+func (c *Context) DeltaTime() float64 {
+	return c.deltaTime
+}
+
+// MouseWheel returns the vertical scroll offset of the current frame.
+func (c *Context) MouseWheel() float32 {
+	return c.frameWheel
+}
+
+// NewFrame starts a new frame. Widgets can be declared until Render is called.
+// The display size is in screen coordinates, those of the cursor positions.
+func (c *Context) NewFrame(width, height int, deltaTime float64) {
+	c.displaySize = mgl32.Vec2{float32(width), float32(height)}
+	c.deltaTime = deltaTime
+
+	c.mouseClicked = c.mousePressedCB
+	c.mouseReleased = c.mouseReleasedCB
+	c.mousePressedCB = [3]bool{}
+	c.mouseReleasedCB = [3]bool{}
+	c.mouseDelta = c.mousePos.Sub(c.lastMousePos)
+	c.lastMousePos = c.mousePos
+	c.frameWheel = c.mouseWheel
+	c.mouseWheel = 0
+
+	// find the top-most window under the mouse, using last frame layout
+	c.hoveredWindow = nil
+	for i := len(c.windowOrder) - 1; i >= 0; i-- {
+		w := c.windowOrder[i]
+		if w.touched && w.rect().Contains(c.mousePos) {
+			c.hoveredWindow = w
+			break
+		}
+	}
+
+	// bring clicked window to front
+	if c.mouseClicked[0] && c.hoveredWindow != nil {
+		c.bringToFront(c.hoveredWindow)
+	}
+
+	c.hotID = 0
+
+	for _, w := range c.windowOrder {
+		w.touched = false
+	}
+}
+
+// Render ends the frame and returns the draw lists of visible windows,
+// from back to front.
+func (c *Context) Render() []*DrawList {
+	// release the active widget once the mouse button is up
+	if !c.mouseDown[0] {
+		c.activeID = 0
+	}
+
+	lists := make([]*DrawList, 0, len(c.windowOrder))
+	for _, w := range c.windowOrder {
+		if w.touched {
+			lists = append(lists, &w.drawList)
+		}
+	}
+	return lists
+}
+
+func (c *Context) bringToFront(w *panel) {
+	for i, other := range c.windowOrder {
+		if other == w {
+			c.windowOrder = append(c.windowOrder[:i], c.windowOrder[i+1:]...)
+			break
+		}
+	}
+	c.windowOrder = append(c.windowOrder, w)
+}
+
+func (c *Context) id(label string) ID {
+	h := fnv.New32a()
+	if c.currentWindow != nil {
+		h.Write([]byte(c.currentWindow.title))
+	}
+	h.Write([]byte(label))
+	return ID(h.Sum32())
+}
+
+func (c *Context) isMouseOwnedBy(w *panel) bool {
+	return c.hoveredWindow == w
+}
@@ -0,0 +1,124 @@
+package debugui
+
+import (
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Vertex .
+type Vertex struct {
+	Position mgl32.Vec2
+	TexCoord mgl32.Vec2
+	Color    mgl32.Vec4
+	// Textured is 1 when the font texture must be sampled.
+	Textured float32
+}
+
+// DrawCmd is a range of indices sharing the same clip rectangle.
+type DrawCmd struct {
+	ClipRect    Rect
+	IndexOffset int
+	IndexCount  int
+}
+
+// DrawList accumulates the geometry of a window.
+type DrawList struct {
+	Vertices []Vertex
+	Indices  []uint32
+	Commands []DrawCmd
+
+	font     *renderer.Font
+	fontSize float32
+}
+
+func (d *DrawList) reset(font *renderer.Font, fontSize float32) {
+	d.Vertices = d.Vertices[:0]
+	d.Indices = d.Indices[:0]
+	d.Commands = d.Commands[:0]
+	d.font = font
+	d.fontSize = fontSize
+}
+
+// PushClipRect starts a new command clipped to rect.
+func (d *DrawList) PushClipRect(rect Rect) {
+	d.Commands = append(d.Commands, DrawCmd{ClipRect: rect, IndexOffset: len(d.Indices)})
+}
+
+func (d *DrawList) addQuad(min, max mgl32.Vec2, texCoords [4]mgl32.Vec2, color mgl32.Vec4, textured float32) {
+	if len(d.Commands) == 0 {
+		d.PushClipRect(Rect{Max: mgl32.Vec2{1 << 20, 1 << 20}})
+	}
+
+	offset := uint32(len(d.Vertices))
+	d.Vertices = append(d.Vertices,
+		Vertex{Position: mgl32.Vec2{min.X(), min.Y()}, TexCoord: texCoords[0], Color: color, Textured: textured},
+		Vertex{Position: mgl32.Vec2{min.X(), max.Y()}, TexCoord: texCoords[1], Color: color, Textured: textured},
+		Vertex{Position: mgl32.Vec2{max.X(), max.Y()}, TexCoord: texCoords[2], Color: color, Textured: textured},
+		Vertex{Position: mgl32.Vec2{max.X(), min.Y()}, TexCoord: texCoords[3], Color: color, Textured: textured},
+	)
+	d.Indices = append(d.Indices, offset, offset+1, offset+2, offset+2, offset+3, offset)
+	d.Commands[len(d.Commands)-1].IndexCount += 6
+}
+
+// AddRectFilled .
+func (d *DrawList) AddRectFilled(rect Rect, color mgl32.Vec4) {
+	d.addQuad(rect.Min, rect.Max, [4]mgl32.Vec2{}, color, 0)
+}
+
+// AddRect adds the outline of rect.
+func (d *DrawList) AddRect(rect Rect, thickness float32, color mgl32.Vec4) {
+	d.AddRectFilled(Rect{Min: rect.Min, Max: mgl32.Vec2{rect.Max.X(), rect.Min.Y() + thickness}}, color)
+	d.AddRectFilled(Rect{Min: mgl32.Vec2{rect.Min.X(), rect.Max.Y() - thickness}, Max: rect.Max}, color)
+	d.AddRectFilled(Rect{Min: rect.Min, Max: mgl32.Vec2{rect.Min.X() + thickness, rect.Max.Y()}}, color)
+	d.AddRectFilled(Rect{Min: mgl32.Vec2{rect.Max.X() - thickness, rect.Min.Y()}, Max: rect.Max}, color)
+}
+
+// AddLine adds a line of the provided thickness between p0 and p1.
+func (d *DrawList) AddLine(p0, p1 mgl32.Vec2, thickness float32, color mgl32.Vec4) {
+	dir := p1.Sub(p0)
+	if dir.Len() == 0 {
+		return
+	}
+	normal := mgl32.Vec2{-dir.Y(), dir.X()}.Normalize().Mul(thickness / 2)
+
+	if len(d.Commands) == 0 {
+		d.PushClipRect(Rect{Max: mgl32.Vec2{1 << 20, 1 << 20}})
+	}
+	offset := uint32(len(d.Vertices))
+	d.Vertices = append(d.Vertices,
+		Vertex{Position: p0.Add(normal), Color: color},
+		Vertex{Position: p0.Sub(normal), Color: color},
+		Vertex{Position: p1.Sub(normal), Color: color},
+		Vertex{Position: p1.Add(normal), Color: color},
+	)
+	d.Indices = append(d.Indices, offset, offset+1, offset+2, offset+2, offset+3, offset)
+	d.Commands[len(d.Commands)-1].IndexCount += 6
+}
+
+// AddText adds str with its top-left corner at pos.
+func (d *DrawList) AddText(pos mgl32.Vec2, str string, color mgl32.Vec4) {
+	d.font.LayoutPixels(str, pos, d.fontSize, func(g renderer.GlyphQuad) {
+		d.addQuad(g.Min, g.Max, g.TexCoords, color, 1)
+	})
+}
+
+// Rect is an axis-aligned rectangle in pixels, with Y pointing down.
+type Rect struct {
+	Min mgl32.Vec2
+	Max mgl32.Vec2
+}
+
+// Contains .
+func (r Rect) Contains(p mgl32.Vec2) bool {
+	return p.X() >= r.Min.X() && p.X() < r.Max.X() && p.Y() >= r.Min.Y() && p.Y() < r.Max.Y()
+}
+
+// Width .
+func (r Rect) Width() float32 {
+	return r.Max.X() - r.Min.X()
+}
+
+// Height .
+func (r Rect) Height() float32 {
+	return r.Max.Y() - r.Min.Y()
+}
@@ -0,0 +1,225 @@
+package debugui
+
+import (
+	"unsafe"
+
+	"github.com/devodev/opengl-experiment/internal/engine/renderer"
+	"github.com/devodev/opengl-experiment/internal/opengl"
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	maxVertices = 65536
+	maxIndices  = maxVertices * 3 / 2
+
+	vertexSize = int(unsafe.Sizeof(Vertex{}))
+
+	vertexLayout = opengl.NewVBOLayout(
+		opengl.VBOLayoutElement{Count: 2, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 2, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+	)
+)
+
+// Renderer draws the GUI draw lists in screen space.
+type Renderer struct {
+	vao           *opengl.VAO
+	vbo           *opengl.VBO
+	shaderProgram *opengl.ShaderProgram
+
+	font *renderer.Font
+
+	// scale from screen coordinates to framebuffer pixels
+	scale             mgl32.Vec2
+	framebufferHeight int
+	// split holds the runs of a list larger than the buffers
+	split DrawList
+}
+
+// NewRenderer .
+func NewRenderer(font *renderer.Font) *Renderer {
+	return &Renderer{font: font}
+}
+
+// Init .
+func (r *Renderer) Init() error {
+	vertexShaderSource := string(append([]byte(vertexShader), byte('\x00')))
+	fragmentShaderSource := string(append([]byte(fragmentShader), byte('\x00')))
+	shaderProgram, err := opengl.NewShaderProgram(vertexShaderSource, fragmentShaderSource)
+	if err != nil {
+		return err
+	}
+
+	// create VBO
+	vbo, err := opengl.NewVBO(maxVertices * vertexSize)
+	if err != nil {
+		return err
+	}
+	vbo.SetLayout(vertexLayout)
+
+	// create IBO
+	ibo := opengl.NewIBO(maxIndices)
+
+	// create VAO and set buffers on it
+	vao := opengl.NewVAO()
+	vao.AddVBO(vbo)
+	vao.SetIBO(ibo)
+
+	r.vao = vao
+	r.vbo = vbo
+	r.shaderProgram = shaderProgram
+
+	return nil
+}
+
+// Render draws lists on top of the current framebuffer, and returns the
+// counters of the draws. width and height are the display size passed to
+// NewFrame, in screen coordinates, mapped to the framebuffer size.
+func (r *Renderer) Render(lists []*DrawList, width, height, framebufferWidth, framebufferHeight int) renderer.Stats {
+	var stats renderer.Stats
+	if width <= 0 || height <= 0 || framebufferWidth <= 0 || framebufferHeight <= 0 {
+		return stats
+	}
+
+	// screen space projection, Y pointing down
+	projection := mgl32.Ortho(0, float32(width), float32(height), 0, -1, 1)
+	r.shaderProgram.SetUniformMatrix4fv("projection", 1, false, &projection[0])
+	r.shaderProgram.SetUniform1i("tex", int32(r.font.Texture().Index()))
+
+	// clip rectangles are scaled to framebuffer pixels
+	r.scale = mgl32.Vec2{
+		float32(framebufferWidth) / float32(width),
+		float32(framebufferHeight) / float32(height),
+	}
+	r.framebufferHeight = framebufferHeight
+
+	// save state modified while rendering
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	scissorEnabled := gl.IsEnabled(gl.SCISSOR_TEST)
+	depthEnabled := gl.IsEnabled(gl.DEPTH_TEST)
+
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Disable(gl.DEPTH_TEST)
+
+	r.font.Texture().Bind()
+	r.shaderProgram.Bind()
+	r.vao.Bind()
+	stats.TextureBinds++
+	stats.ShaderBinds++
+
+	for _, list := range lists {
+		if len(list.Indices) == 0 {
+			continue
+		}
+		if len(list.Vertices) > maxVertices || len(list.Indices) > maxIndices {
+			r.renderSplit(list, &stats)
+			continue
+		}
+		r.upload(list, &stats)
+		for _, cmd := range list.Commands {
+			r.draw(cmd.ClipRect, cmd.IndexOffset, cmd.IndexCount, &stats)
+		}
+	}
+
+	r.vao.Unbind()
+	r.shaderProgram.Unbind()
+	r.font.Texture().Unbind()
+
+	// restore state
+	gl.PolygonMode(gl.FRONT_AND_BACK, uint32(polygonMode[0]))
+	if !scissorEnabled {
+		gl.Disable(gl.SCISSOR_TEST)
+	}
+	if depthEnabled {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+	return stats
+}
+
+// renderSplit draws a list larger than the buffers, a run of triangles
+// at a time, the indices of a run being rebased on the lowest vertex it uses.
+func (r *Renderer) renderSplit(list *DrawList, stats *renderer.Stats) {
+	for _, cmd := range list.Commands {
+		start, end := cmd.IndexOffset, cmd.IndexOffset+cmd.IndexCount
+		for start < end {
+			first, last := ^uint32(0), uint32(0)
+			n := 0
+			for start+n+3 <= end && n+3 <= maxIndices {
+				lo, hi := first, last
+				for _, idx := range list.Indices[start+n : start+n+3] {
+					if idx < lo {
+						lo = idx
+					}
+					if idx > hi {
+						hi = idx
+					}
+				}
+				if int(hi-lo) >= maxVertices {
+					break
+				}
+				first, last = lo, hi
+				n += 3
+			}
+			if n == 0 {
+				// a triangle spanning more vertices than the buffer holds
+				break
+			}
+
+			r.split.Vertices = append(r.split.Vertices[:0], list.Vertices[first:last+1]...)
+			r.split.Indices = r.split.Indices[:0]
+			for _, idx := range list.Indices[start : start+n] {
+				r.split.Indices = append(r.split.Indices, idx-first)
+			}
+			r.upload(&r.split, stats)
+			r.draw(cmd.ClipRect, 0, n, stats)
+			start += n
+		}
+	}
+}
+
+func (r *Renderer) upload(list *DrawList, stats *renderer.Stats) {
+	r.vbo.SetData(list)
+	r.vao.IBO().SetData(list)
+	r.vao.IBO().Bind()
+	stats.Vertices += len(list.Vertices)
+	stats.UploadBytes += list.VBOSize() + 4*int(list.IBOCount())
+}
+
+func (r *Renderer) draw(clip Rect, indexOffset, indexCount int, stats *renderer.Stats) {
+	if indexCount == 0 {
+		return
+	}
+	gl.Scissor(
+		int32(clip.Min.X()*r.scale.X()),
+		int32(float32(r.framebufferHeight)-clip.Max.Y()*r.scale.Y()),
+		int32(clip.Width()*r.scale.X()),
+		int32(clip.Height()*r.scale.Y()),
+	)
+	gl.DrawElements(gl.TRIANGLES, int32(indexCount), gl.UNSIGNED_INT, gl.PtrOffset(indexOffset*4))
+	stats.DrawCalls++
+	stats.Indices += indexCount
+}
+
+// VBOGLPtr implements the VBOData interface.
+func (d *DrawList) VBOGLPtr() unsafe.Pointer {
+	return gl.Ptr(d.Vertices)
+}
+
+// VBOSize implements the VBOData interface.
+func (d *DrawList) VBOSize() int {
+	return vertexSize * len(d.Vertices)
+}
+
+// IBOGLPtr implements the IBOData interface.
+func (d *DrawList) IBOGLPtr() unsafe.Pointer {
+	return gl.Ptr(d.Indices)
+}
+
+// IBOCount implements the IBOData interface.
+func (d *DrawList) IBOCount() int32 {
+	return int32(len(d.Indices))
+}
@@ -0,0 +1,42 @@
+package debugui
+
+const (
+	vertexShader = `
+#version 460 core
+layout (location = 0) in vec2 position;
+layout (location = 1) in vec2 texCoord;
+layout (location = 2) in vec4 color;
+layout (location = 3) in float textured;
+
+out vec2 fragTexCoord;
+out vec4 fragColor;
+out float fragTextured;
+
+uniform mat4 projection;
+
+void main() {
+    fragTexCoord = texCoord;
+    fragColor = color;
+    fragTextured = textured;
+    gl_Position = projection * vec4(position, 0.0, 1.0);
+}
+    `
+
+	fragmentShader = `
+#version 460 core
+layout (location = 0) out vec4 outColor;
+
+in vec2 fragTexCoord;
+in vec4 fragColor;
+in float fragTextured;
+
+uniform sampler2D tex;
+
+void main() {
+    outColor = fragColor;
+    if (fragTextured > 0.5) {
+        outColor *= texture(tex, fragTexCoord);
+    }
+}
+    `
+)
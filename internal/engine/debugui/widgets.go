@@ -0,0 +1,267 @@
+package debugui
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Begin starts a window. Widgets declared until End are laid out in it.
+// It returns false when the window is collapsed, in which case widgets
+// can be skipped, but End must still be called.
+func (c *Context) Begin(title string) bool {
+	id := c.id(title)
+	w, ok := c.windows[id]
+	if !ok {
+		w = &panel{
+			id:    id,
+			title: title,
+			pos:   c.nextWindowPos,
+			size:  mgl32.Vec2{defaultWindowWidth, c.titleBarHeight()},
+		}
+		c.windows[id] = w
+		c.windowOrder = append(c.windowOrder, w)
+		c.nextWindowPos = c.nextWindowPos.Add(mgl32.Vec2{defaultWindowWidth + defaultPadding, 0})
+	}
+	c.currentWindow = w
+	w.touched = true
+	w.drawList.reset(c.font, c.fontSize)
+
+	// title bar: drag to move, click to collapse
+	titleBar := Rect{Min: w.pos, Max: w.pos.Add(mgl32.Vec2{w.size.X(), c.titleBarHeight()})}
+	titleID := c.id("#title")
+	if c.isMouseOwnedBy(w) && titleBar.Contains(c.mousePos) && c.mouseClicked[0] {
+		c.activeID = titleID
+	}
+	if c.activeID == titleID {
+		w.pos = w.pos.Add(c.mouseDelta)
+		if c.mouseReleased[0] && c.mouseDelta.Len() == 0 && titleBar.Contains(c.mousePos) {
+			w.collapsed = !w.collapsed
+		}
+		titleBar = Rect{Min: w.pos, Max: w.pos.Add(mgl32.Vec2{w.size.X(), c.titleBarHeight()})}
+	}
+
+	// background uses the size computed at the end of the previous frame
+	if !w.collapsed {
+		w.drawList.AddRectFilled(w.rect(), colorWindowBg)
+	}
+	w.drawList.AddRectFilled(titleBar, colorTitleBg)
+	arrow := "v "
+	if w.collapsed {
+		arrow = "> "
+	}
+	w.drawList.AddText(titleBar.Min.Add(mgl32.Vec2{defaultPadding, defaultPadding / 2}), arrow+title, colorText)
+
+	w.cursor = mgl32.Vec2{w.pos.X() + defaultPadding, titleBar.Max.Y() + defaultPadding}
+	w.lastItemEnd = w.cursor
+	w.sameLine = false
+
+	return !w.collapsed
+}
+
+// End finishes the current window.
+func (c *Context) End() {
+	w := c.currentWindow
+	if w == nil {
+		return
+	}
+	if w.collapsed {
+		w.size = mgl32.Vec2{w.size.X(), c.titleBarHeight()}
+	} else {
+		w.size = mgl32.Vec2{w.size.X(), w.cursor.Y() - w.pos.Y() + defaultPadding - defaultItemSpacing}
+		w.drawList.AddRect(w.rect(), 1, colorBorder)
+	}
+	c.currentWindow = nil
+}
+
+// SameLine places the next widget on the same line as the previous one.
+func (c *Context) SameLine() {
+	if c.currentWindow != nil {
+		c.currentWindow.sameLine = true
+	}
+}
+
+// Text adds a formatted label.
+func (c *Context) Text(format string, args ...interface{}) {
+	c.text(fmt.Sprintf(format, args...), colorText)
+}
+
+// TextDisabled adds a formatted label using a dimmed color.
+func (c *Context) TextDisabled(format string, args ...interface{}) {
+	c.text(fmt.Sprintf(format, args...), colorTextDisabled)
+}
+
+func (c *Context) text(str string, color mgl32.Vec4) {
+	w := c.currentWindow
+	if w == nil || w.collapsed {
+		return
+	}
+	width, height := c.font.Measure(str, c.fontSize)
+	rect := c.itemRect(width, height)
+	w.drawList.AddText(rect.Min, str, color)
+}
+
+// Separator adds a horizontal line.
+func (c *Context) Separator() {
+	w := c.currentWindow
+	if w == nil || w.collapsed {
+		return
+	}
+	rect := c.itemRect(w.size.X()-2*defaultPadding, 1)
+	w.drawList.AddRectFilled(rect, colorBorder)
+}
+
+// Button adds a button and returns true when it was clicked.
+func (c *Context) Button(label string) bool {
+	w := c.currentWindow
+	if w == nil || w.collapsed {
+		return false
+	}
+	width, height := c.font.Measure(label, c.fontSize)
+	rect := c.itemRect(width+2*defaultPadding, height+defaultPadding)
+	pressed, hovered, held := c.buttonBehavior(c.id(label), rect)
+
+	color := colorFrameBg
+	if held {
+		color = colorFrameActive
+	} else if hovered {
+		color = colorFrameHovered
+	}
+	w.drawList.AddRectFilled(rect, color)
+	w.drawList.AddText(rect.Min.Add(mgl32.Vec2{defaultPadding, defaultPadding / 2}), label, colorText)
+	return pressed
+}
+
+// Checkbox adds a checkbox bound to v and returns true when v changed.
+func (c *Context) Checkbox(label string, v *bool) bool {
+	w := c.currentWindow
+	if w == nil || w.collapsed {
+		return false
+	}
+	width, height := c.font.Measure(label, c.fontSize)
+	box := height
+	rect := c.itemRect(box+defaultPadding+width, height)
+	pressed, hovered, held := c.buttonBehavior(c.id(label), rect)
+	if pressed {
+		*v = !*v
+	}
+
+	boxRect := Rect{Min: rect.Min, Max: rect.Min.Add(mgl32.Vec2{box, box})}
+	color := colorFrameBg
+	if held {
+		color = colorFrameActive
+	} else if hovered {
+		color = colorFrameHovered
+	}
+	w.drawList.AddRectFilled(boxRect, color)
+	if *v {
+		inset := box / 4
+		w.drawList.AddRectFilled(Rect{
+			Min: boxRect.Min.Add(mgl32.Vec2{inset, inset}),
+			Max: boxRect.Max.Sub(mgl32.Vec2{inset, inset}),
+		}, colorCheckMark)
+	}
+	w.drawList.AddText(mgl32.Vec2{boxRect.Max.X() + defaultPadding, rect.Min.Y()}, label, colorText)
+	return pressed
+}
+
+// SliderFloat adds a slider bound to v in the range [min, max]
+// and returns true when v changed.
+func (c *Context) SliderFloat(label string, v *float32, min, max float32) bool {
+	w := c.currentWindow
+	if w == nil || w.collapsed {
+		return false
+	}
+	_, height := c.font.Measure(label, c.fontSize)
+	frameWidth := (w.size.X() - 2*defaultPadding) * 0.6
+	rect := c.itemRect(frameWidth, height+defaultPadding)
+	_, hovered, held := c.buttonBehavior(c.id(label), rect)
+
+	changed := false
+	if held && max > min {
+		t := (c.mousePos.X() - rect.Min.X()) / rect.Width()
+		value := min + mgl32.Clamp(t, 0, 1)*(max-min)
+		if value != *v {
+			*v = value
+			changed = true
+		}
+	}
+
+	color := colorFrameBg
+	if held {
+		color = colorFrameActive
+	} else if hovered {
+		color = colorFrameHovered
+	}
+	w.drawList.AddRectFilled(rect, color)
+	if max > min {
+		t := mgl32.Clamp((*v-min)/(max-min), 0, 1)
+		grab := rect.Min.X() + t*(rect.Width()-defaultPadding)
+		w.drawList.AddRectFilled(Rect{
+			Min: mgl32.Vec2{grab, rect.Min.Y() + 1},
+			Max: mgl32.Vec2{grab + defaultPadding, rect.Max.Y() - 1},
+		}, colorCheckMark)
+	}
+	w.drawList.AddText(rect.Min.Add(mgl32.Vec2{defaultPadding, defaultPadding / 2}), fmt.Sprintf("%.3f", *v), colorText)
+	w.drawList.AddText(mgl32.Vec2{rect.Max.X() + defaultPadding, rect.Min.Y() + defaultPadding/2}, label, colorText)
+	return changed
+}
+
+// PlotLines adds a line graph of values scaled between min and max.
+func (c *Context) PlotLines(label string, values []float32, min, max float32, height float32) {
+	w := c.currentWindow
+	if w == nil || w.collapsed {
+		return
+	}
+	rect := c.itemRect(w.size.X()-2*defaultPadding, height)
+	w.drawList.AddRectFilled(rect, colorFrameBg)
+	if len(values) > 1 && max > min {
+		step := rect.Width() / float32(len(values)-1)
+		point := func(i int) mgl32.Vec2 {
+			t := mgl32.Clamp((values[i]-min)/(max-min), 0, 1)
+			return mgl32.Vec2{rect.Min.X() + float32(i)*step, rect.Max.Y() - t*rect.Height()}
+		}
+		for i := 0; i+1 < len(values); i++ {
+			w.drawList.AddLine(point(i), point(i+1), 1, colorPlotLines)
+		}
+	}
+	w.drawList.AddText(rect.Min.Add(mgl32.Vec2{defaultPadding / 2, 0}), label, colorText)
+}
+
+// itemRect reserves space for a widget in the current window.
+func (c *Context) itemRect(width, height float32) Rect {
+	w := c.currentWindow
+	var pos mgl32.Vec2
+	if w.sameLine {
+		pos = mgl32.Vec2{w.lastItemEnd.X() + defaultItemSpacing, w.lastItemEnd.Y()}
+		w.sameLine = false
+	} else {
+		pos = w.cursor
+	}
+	rect := Rect{Min: pos, Max: pos.Add(mgl32.Vec2{width, height})}
+	w.lastItemEnd = mgl32.Vec2{rect.Max.X(), rect.Min.Y()}
+	if bottom := rect.Max.Y() + defaultItemSpacing; bottom > w.cursor.Y() {
+		w.cursor = mgl32.Vec2{w.cursor.X(), bottom}
+	}
+	return rect
+}
+
+// buttonBehavior handles the interaction of a clickable widget.
+func (c *Context) buttonBehavior(id ID, rect Rect) (pressed, hovered, held bool) {
+	hovered = c.isMouseOwnedBy(c.currentWindow) && rect.Contains(c.mousePos)
+	if hovered {
+		c.hotID = id
+		if c.mouseClicked[0] {
+			c.activeID = id
+		}
+	}
+	held = c.activeID == id && c.mouseDown[0]
+	if c.activeID == id && c.mouseReleased[0] {
+		pressed = hovered
+	}
+	return pressed, hovered, held
+}
+
+func (c *Context) titleBarHeight() float32 {
+	return c.fontSize*c.font.LineHeight() + defaultPadding
+}
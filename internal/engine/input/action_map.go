@@ -0,0 +1,451 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Errors
+var (
+	ErrUnknownContext = errors.New("unknown input context")
+	ErrEmptyStack     = errors.New("input context stack is empty")
+)
+
+// Source provides the state of the physical inputs during a frame.
+// It is implemented by window.Window and window.InputState.
+type Source interface {
+	IsKeyPressed(key window.Key) bool
+	GetKeyDown(key window.Key) bool
+	GetKeyUp(key window.Key) bool
+	GetMods() window.ModifierKey
+	IsMouseButtonPressed(button window.MouseButton) bool
+	GetMouseButtonDown(button window.MouseButton) bool
+	GetMouseButtonUp(button window.MouseButton) bool
+	GetCursorDelta() (float64, float64)
+	GetScroll() (float64, float64)
+	GetGamepads() []window.Joystick
+	IsGamepadButtonPressed(j window.Joystick, button window.GamepadButton) bool
+	GetGamepadButtonDown(j window.Joystick, button window.GamepadButton) bool
+	GetGamepadButtonUp(j window.Joystick, button window.GamepadButton) bool
+	GetGamepadAxis(j window.Joystick, axis window.GamepadAxis) float32
+}
+
+// WithoutMouse returns a source hiding the mouse buttons, motion and
+// scroll of src, for frames where the mouse is used by something else,
+// such as a GUI.
+func WithoutMouse(src Source) Source {
+	return mouseless{src}
+}
+
+type mouseless struct {
+	Source
+}
+
+func (mouseless) IsMouseButtonPressed(window.MouseButton) bool { return false }
+func (mouseless) GetMouseButtonDown(window.MouseButton) bool   { return false }
+func (mouseless) GetMouseButtonUp(window.MouseButton) bool     { return false }
+func (mouseless) GetCursorDelta() (float64, float64)           { return 0, 0 }
+func (mouseless) GetScroll() (float64, float64)                { return 0, 0 }
+
+// AxisBinding binds an axis to either an analog input, such as a gamepad
+// stick, or a pair of inputs producing 1 and -1, such as two keys.
+type AxisBinding struct {
+	Input    *Input `json:"input,omitempty"`
+	Positive *Input `json:"positive,omitempty"`
+	Negative *Input `json:"negative,omitempty"`
+	// Scale multiplies the value, 0 being the same as 1.
+	// A negative scale inverts the axis.
+	Scale float32 `json:"scale,omitempty"`
+}
+
+// AnalogAxis binds an axis to an analog input.
+func AnalogAxis(in Input, scale float32) AxisBinding {
+	return AxisBinding{Input: &in, Scale: scale}
+}
+
+// DigitalAxis binds an axis to a pair of inputs.
+func DigitalAxis(positive, negative Input) AxisBinding {
+	return AxisBinding{Positive: &positive, Negative: &negative}
+}
+
+func (b AxisBinding) value(src Source, held chords) float32 {
+	var v float32
+	if b.Input != nil && !held.hides(*b.Input) {
+		v += inputValue(src, *b.Input)
+	}
+	if b.Positive != nil && !held.hides(*b.Positive) {
+		v += inputValue(src, *b.Positive)
+	}
+	if b.Negative != nil && !held.hides(*b.Negative) {
+		v -= inputValue(src, *b.Negative)
+	}
+	if b.Scale != 0 {
+		v *= b.Scale
+	}
+	return v
+}
+
+// Context is a named set of action and axis bindings, such as the
+// bindings of gameplay or of a menu.
+type Context struct {
+	Name string `json:"-"`
+	// Exclusive hides the contexts below this one on the stack, even
+	// for the actions and axes it does not bind.
+	Exclusive bool                     `json:"exclusive,omitempty"`
+	Actions   map[string][]Input       `json:"actions,omitempty"`
+	Axes      map[string][]AxisBinding `json:"axes,omitempty"`
+}
+
+// NewContext .
+func NewContext(name string) *Context {
+	return &Context{
+		Name:    name,
+		Actions: make(map[string][]Input),
+		Axes:    make(map[string][]AxisBinding),
+	}
+}
+
+// Bind adds inputs triggering action.
+func (c *Context) Bind(action string, inputs ...Input) *Context {
+	if c.Actions == nil {
+		c.Actions = make(map[string][]Input)
+	}
+	c.Actions[action] = append(c.Actions[action], inputs...)
+	return c
+}
+
+// Rebind replaces the inputs triggering action.
+func (c *Context) Rebind(action string, inputs ...Input) *Context {
+	if c.Actions == nil {
+		c.Actions = make(map[string][]Input)
+	}
+	c.Actions[action] = append([]Input(nil), inputs...)
+	return c
+}
+
+// BindAxis adds bindings to axis.
+func (c *Context) BindAxis(axis string, bindings ...AxisBinding) *Context {
+	if c.Axes == nil {
+		c.Axes = make(map[string][]AxisBinding)
+	}
+	c.Axes[axis] = append(c.Axes[axis], bindings...)
+	return c
+}
+
+// RebindAxis replaces the bindings of axis.
+func (c *Context) RebindAxis(axis string, bindings ...AxisBinding) *Context {
+	if c.Axes == nil {
+		c.Axes = make(map[string][]AxisBinding)
+	}
+	c.Axes[axis] = append([]AxisBinding(nil), bindings...)
+	return c
+}
+
+type actionState struct {
+	pressed bool
+	down    bool
+	up      bool
+}
+
+// ActionMap holds the input contexts and a stack of the active ones. Update
+// must be called once per frame, after polling events, to compute the state
+// of the actions and axes bound in the active contexts. An action or axis
+// bound in several contexts uses the bindings of the top-most one.
+//
+// A key binding is ignored while a binding of the same key requiring more
+// modifiers is held, so that Ctrl+S does not also trigger S.
+type ActionMap struct {
+	contexts map[string]*Context
+	stack    []*Context
+
+	actions map[string]actionState
+	axes    map[string]float32
+}
+
+// NewActionMap .
+func NewActionMap() *ActionMap {
+	return &ActionMap{
+		contexts: make(map[string]*Context),
+		actions:  make(map[string]actionState),
+		axes:     make(map[string]float32),
+	}
+}
+
+// AddContext adds a context, replacing the one with the same name,
+// including on the stack.
+func (m *ActionMap) AddContext(c *Context) {
+	if old, ok := m.contexts[c.Name]; ok {
+		for i, active := range m.stack {
+			if active == old {
+				m.stack[i] = c
+			}
+		}
+	}
+	m.contexts[c.Name] = c
+}
+
+// GetContext returns the context named name, or nil.
+func (m *ActionMap) GetContext(name string) *Context {
+	return m.contexts[name]
+}
+
+// Contexts returns the contexts, sorted by name.
+func (m *ActionMap) Contexts() []*Context {
+	contexts := make([]*Context, 0, len(m.contexts))
+	for _, c := range m.contexts {
+		contexts = append(contexts, c)
+	}
+	sortContexts(contexts)
+	return contexts
+}
+
+// PushContext activates the context named name on top of the stack.
+func (m *ActionMap) PushContext(name string) error {
+	c, ok := m.contexts[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownContext, name)
+	}
+	m.stack = append(m.stack, c)
+	return nil
+}
+
+// PopContext deactivates the context on top of the stack, and returns its name.
+func (m *ActionMap) PopContext() (string, error) {
+	if len(m.stack) == 0 {
+		return "", ErrEmptyStack
+	}
+	c := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return c.Name, nil
+}
+
+// ActiveContexts returns the names of the contexts on the stack, bottom first.
+func (m *ActionMap) ActiveContexts() []string {
+	names := make([]string, len(m.stack))
+	for i, c := range m.stack {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// visibleContexts returns the active contexts which are not
+// hidden by an exclusive one, top first.
+func (m *ActionMap) visibleContexts() []*Context {
+	var contexts []*Context
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		contexts = append(contexts, m.stack[i])
+		if m.stack[i].Exclusive {
+			break
+		}
+	}
+	return contexts
+}
+
+// Update computes the state of the actions and axes from src.
+func (m *ActionMap) Update(src Source) {
+	actions := make(map[string]actionState, len(m.actions))
+	axes := make(map[string]float32, len(m.axes))
+
+	contexts := m.visibleContexts()
+	held := heldChords(src, contexts)
+	for _, c := range contexts {
+		for name, inputs := range c.Actions {
+			if _, ok := actions[name]; ok {
+				continue
+			}
+			var state actionState
+			for _, in := range inputs {
+				if held.hides(in) {
+					continue
+				}
+				pressed, down, up := inputEdges(src, in)
+				state.pressed = state.pressed || pressed
+				state.down = state.down || down
+				state.up = state.up || up
+			}
+			// edges of inputs pressed and released within the frame are
+			// kept, the held state is used for the other inputs
+			prev := m.actions[name].pressed
+			state.down = state.down && !prev || state.pressed && !prev
+			state.up = state.up && !state.pressed || !state.pressed && prev
+			actions[name] = state
+		}
+		for name, bindings := range c.Axes {
+			if _, ok := axes[name]; ok {
+				continue
+			}
+			// the binding with the largest magnitude wins, so that
+			// keys and sticks bound together do not add up
+			var value float32
+			for _, b := range bindings {
+				if v := b.value(src, held); abs(v) > abs(value) {
+					value = v
+				}
+			}
+			axes[name] = value
+		}
+	}
+
+	// actions no longer bound, e.g. after popping their
+	// context, are released
+	for name, state := range m.actions {
+		if _, ok := actions[name]; !ok && state.pressed {
+			actions[name] = actionState{up: true}
+		}
+	}
+
+	m.actions = actions
+	m.axes = axes
+}
+
+// Pressed reports whether the action is held down.
+func (m *ActionMap) Pressed(action string) bool {
+	return m.actions[action].pressed
+}
+
+// Down reports whether the action was pressed during the last frame.
+func (m *ActionMap) Down(action string) bool {
+	return m.actions[action].down
+}
+
+// Up reports whether the action was released during the last frame.
+func (m *ActionMap) Up(action string) bool {
+	return m.actions[action].up
+}
+
+// Axis returns the value of the axis, from -1 to 1 for keys and
+// gamepad inputs. Mouse axes are in screen coordinates.
+func (m *ActionMap) Axis(axis string) float32 {
+	return m.axes[axis]
+}
+
+// chords are the modifiers of the key bindings held during a frame, by key.
+type chords map[window.Key][]window.ModifierKey
+
+// heldChords returns the key bindings with modifiers of contexts held in src.
+func heldChords(src Source, contexts []*Context) chords {
+	var held chords
+	add := func(in *Input) {
+		if in == nil || in.Device != DeviceKeyboard || in.Mods == 0 || !isPressed(src, *in) {
+			return
+		}
+		if held == nil {
+			held = make(chords)
+		}
+		key := window.Key(in.Code)
+		held[key] = append(held[key], in.Mods)
+	}
+	for _, c := range contexts {
+		for _, inputs := range c.Actions {
+			for i := range inputs {
+				add(&inputs[i])
+			}
+		}
+		for _, bindings := range c.Axes {
+			for _, b := range bindings {
+				add(b.Input)
+				add(b.Positive)
+				add(b.Negative)
+			}
+		}
+	}
+	return held
+}
+
+// hides reports whether in is a key binding hidden by a held
+// binding of the same key requiring more modifiers.
+func (c chords) hides(in Input) bool {
+	if in.Device != DeviceKeyboard {
+		return false
+	}
+	for _, mods := range c[window.Key(in.Code)] {
+		if mods != in.Mods && mods.Has(in.Mods) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPressed reports whether in is held down, axes being
+// pressed past a threshold.
+func isPressed(src Source, in Input) bool {
+	switch in.Device {
+	case DeviceKeyboard:
+		return src.IsKeyPressed(window.Key(in.Code)) && src.GetMods().Has(in.Mods)
+	case DeviceMouse:
+		return src.IsMouseButtonPressed(window.MouseButton(in.Code))
+	case DeviceGamepad:
+		for _, j := range src.GetGamepads() {
+			if src.IsGamepadButtonPressed(j, window.GamepadButton(in.Code)) {
+				return true
+			}
+		}
+		return false
+	}
+	return abs(inputValue(src, in)) >= axisThreshold
+}
+
+// inputEdges returns whether in is held down, and was pressed or released
+// during the frame. Axes have no edges, as their state is not tracked.
+func inputEdges(src Source, in Input) (pressed, down, up bool) {
+	pressed = isPressed(src, in)
+	switch in.Device {
+	case DeviceKeyboard:
+		key := window.Key(in.Code)
+		mods := src.GetMods().Has(in.Mods)
+		down = src.GetKeyDown(key) && mods
+		up = src.GetKeyUp(key)
+	case DeviceMouse:
+		down = src.GetMouseButtonDown(window.MouseButton(in.Code))
+		up = src.GetMouseButtonUp(window.MouseButton(in.Code))
+	case DeviceGamepad:
+		for _, j := range src.GetGamepads() {
+			down = down || src.GetGamepadButtonDown(j, window.GamepadButton(in.Code))
+			up = up || src.GetGamepadButtonUp(j, window.GamepadButton(in.Code))
+		}
+	}
+	return pressed, down, up
+}
+
+// inputValue returns the value of in, 0 or 1 for keys and buttons.
+func inputValue(src Source, in Input) float32 {
+	var v float32
+	switch in.Device {
+	case DeviceKeyboard, DeviceMouse, DeviceGamepad:
+		if isPressed(src, in) {
+			return 1
+		}
+		return 0
+	case DeviceMouseAxis:
+		dx, dy := src.GetCursorDelta()
+		sx, sy := src.GetScroll()
+		switch MouseAxis(in.Code) {
+		case MouseAxisX:
+			v = float32(dx)
+		case MouseAxisY:
+			v = float32(dy)
+		case MouseAxisScrollX:
+			v = float32(sx)
+		case MouseAxisScrollY:
+			v = float32(sy)
+		}
+	case DeviceGamepadAxis:
+		// use the gamepad pushed the furthest
+		for _, j := range src.GetGamepads() {
+			if a := src.GetGamepadAxis(j, window.GamepadAxis(in.Code)); abs(a) > abs(v) {
+				v = a
+			}
+		}
+	}
+	if in.Direction != 0 {
+		v = float32(math.Max(0, float64(v)*float64(in.Direction)))
+	}
+	return v
+}
+
+func abs(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
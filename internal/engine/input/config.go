@@ -0,0 +1,84 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// config is the JSON representation of the contexts of an ActionMap:
+//
+//	{
+//	  "contexts": {
+//	    "gameplay": {
+//	      "actions": {"jump": ["key:Space", "gamepad:A"]},
+//	      "axes": {"move_x": [
+//	        {"positive": "key:D", "negative": "key:A"},
+//	        {"input": "gamepad_axis:LeftX"}
+//	      ]}
+//	    },
+//	    "menu": {"exclusive": true, "actions": {"back": ["key:Escape"]}}
+//	  }
+//	}
+type config struct {
+	Contexts map[string]*Context `json:"contexts"`
+}
+
+// Load reads contexts from JSON, replacing the contexts with the same
+// names. The stack is left unchanged.
+func (m *ActionMap) Load(r io.Reader) error {
+	var cfg config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return fmt.Errorf("error decoding input config: %s", err)
+	}
+	for name, c := range cfg.Contexts {
+		if c == nil {
+			c = NewContext(name)
+		}
+		c.Name = name
+		m.AddContext(c)
+	}
+	return nil
+}
+
+// LoadFile reads contexts from the JSON file at path.
+func (m *ActionMap) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.Load(f)
+}
+
+// Save writes every context as JSON.
+func (m *ActionMap) Save(w io.Writer) error {
+	cfg := config{Contexts: m.contexts}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cfg); err != nil {
+		return fmt.Errorf("error encoding input config: %s", err)
+	}
+	return nil
+}
+
+// SaveFile writes every context to the JSON file at path.
+func (m *ActionMap) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func sortContexts(contexts []*Context) {
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+}
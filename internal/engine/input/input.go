@@ -0,0 +1,273 @@
+// Package input maps physical inputs to named actions and axes, grouped in
+// contexts which can be pushed and popped, so that bindings can be changed
+// and saved without touching the code querying them.
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Device is the kind of an Input.
+type Device int
+
+// Input devices
+const (
+	DeviceKeyboard Device = iota
+	DeviceMouse
+	DeviceMouseAxis
+	DeviceGamepad
+	DeviceGamepadAxis
+)
+
+var deviceNames = map[Device]string{
+	DeviceKeyboard:    "key",
+	DeviceMouse:       "mouse",
+	DeviceMouseAxis:   "mouse_axis",
+	DeviceGamepad:     "gamepad",
+	DeviceGamepadAxis: "gamepad_axis",
+}
+
+func (d Device) String() string {
+	if name, ok := deviceNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("Device(%d)", int(d))
+}
+
+// MouseAxis is a relative motion of the mouse during a frame.
+type MouseAxis int
+
+// Mouse axes
+const (
+	MouseAxisX MouseAxis = iota
+	MouseAxisY
+	MouseAxisScrollX
+	MouseAxisScrollY
+)
+
+var (
+	mouseButtonNames = map[int]string{
+		int(window.MouseButtonLeft):   "Left",
+		int(window.MouseButtonRight):  "Right",
+		int(window.MouseButtonMiddle): "Middle",
+		int(window.MouseButton4):      "4",
+		int(window.MouseButton5):      "5",
+		int(window.MouseButton6):      "6",
+		int(window.MouseButton7):      "7",
+		int(window.MouseButton8):      "8",
+	}
+	mouseAxisNames = map[int]string{
+		int(MouseAxisX):       "X",
+		int(MouseAxisY):       "Y",
+		int(MouseAxisScrollX): "ScrollX",
+		int(MouseAxisScrollY): "ScrollY",
+	}
+	gamepadButtonNames = map[int]string{
+		int(window.GamepadButtonA):           "A",
+		int(window.GamepadButtonB):           "B",
+		int(window.GamepadButtonX):           "X",
+		int(window.GamepadButtonY):           "Y",
+		int(window.GamepadButtonLeftBumper):  "LeftBumper",
+		int(window.GamepadButtonRightBumper): "RightBumper",
+		int(window.GamepadButtonBack):        "Back",
+		int(window.GamepadButtonStart):       "Start",
+		int(window.GamepadButtonGuide):       "Guide",
+		int(window.GamepadButtonLeftThumb):   "LeftThumb",
+		int(window.GamepadButtonRightThumb):  "RightThumb",
+		int(window.GamepadButtonDpadUp):      "DpadUp",
+		int(window.GamepadButtonDpadRight):   "DpadRight",
+		int(window.GamepadButtonDpadDown):    "DpadDown",
+		int(window.GamepadButtonDpadLeft):    "DpadLeft",
+	}
+	gamepadAxisNames = map[int]string{
+		int(window.GamepadAxisLeftX):        "LeftX",
+		int(window.GamepadAxisLeftY):        "LeftY",
+		int(window.GamepadAxisRightX):       "RightX",
+		int(window.GamepadAxisRightY):       "RightY",
+		int(window.GamepadAxisLeftTrigger):  "LeftTrigger",
+		int(window.GamepadAxisRightTrigger): "RightTrigger",
+	}
+	keyCodes = make(map[string]int)
+)
+
+func init() {
+	for k := window.KeySpace; k <= window.KeyLast; k++ {
+		if name := k.String(); !strings.HasPrefix(name, "Key(") {
+			keyCodes[strings.ToLower(name)] = int(k)
+		}
+	}
+}
+
+func codeNames(d Device) map[int]string {
+	switch d {
+	case DeviceMouse:
+		return mouseButtonNames
+	case DeviceMouseAxis:
+		return mouseAxisNames
+	case DeviceGamepad:
+		return gamepadButtonNames
+	case DeviceGamepadAxis:
+		return gamepadAxisNames
+	}
+	return nil
+}
+
+// axisThreshold is the position past which an axis bound to an action,
+// or as an axis direction, is considered pressed.
+const axisThreshold = 0.5
+
+// Input is a physical input, such as a key or a gamepad axis. Its text form,
+// used in configuration files, is "device:name", such as "key:W",
+// "key:Ctrl+S", "mouse:Left", "gamepad:A", "gamepad_axis:LeftX",
+// "gamepad_axis:LeftY-" or "mouse_axis:ScrollY".
+type Input struct {
+	Device Device
+	// Code is the key, button or axis of the device
+	Code int
+	// Mods are the modifiers required with a key
+	Mods window.ModifierKey
+	// Direction restricts an axis to its positive (1) or negative (-1)
+	// half, read as a value from 0 to 1. It is 0 for the whole axis.
+	Direction int
+}
+
+// Key returns the input of key, with the modifiers mods.
+func Key(key window.Key, mods window.ModifierKey) Input {
+	return Input{Device: DeviceKeyboard, Code: int(key), Mods: mods}
+}
+
+// MouseButton .
+func MouseButton(button window.MouseButton) Input {
+	return Input{Device: DeviceMouse, Code: int(button)}
+}
+
+// MouseAxisInput .
+func MouseAxisInput(axis MouseAxis) Input {
+	return Input{Device: DeviceMouseAxis, Code: int(axis)}
+}
+
+// GamepadButton .
+func GamepadButton(button window.GamepadButton) Input {
+	return Input{Device: DeviceGamepad, Code: int(button)}
+}
+
+// GamepadAxis returns the input of axis, restricted to a direction when not 0.
+func GamepadAxis(axis window.GamepadAxis, direction int) Input {
+	return Input{Device: DeviceGamepadAxis, Code: int(axis), Direction: direction}
+}
+
+// String returns the text form of the input.
+func (i Input) String() string {
+	var name string
+	if i.Device == DeviceKeyboard {
+		name = window.Key(i.Code).String()
+		if i.Mods != 0 {
+			name = i.Mods.String() + "+" + name
+		}
+	} else if n, ok := codeNames(i.Device)[i.Code]; ok {
+		name = n
+	} else {
+		name = strconv.Itoa(i.Code)
+	}
+	switch i.Direction {
+	case 1:
+		name += "+"
+	case -1:
+		name += "-"
+	}
+	return i.Device.String() + ":" + name
+}
+
+// ParseInput parses the text form of an input. Names are case insensitive.
+func ParseInput(s string) (Input, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return Input{}, fmt.Errorf("invalid input %q: expected device:name", s)
+	}
+	deviceName, name := parts[0], parts[1]
+
+	var in Input
+	found := false
+	for d, n := range deviceNames {
+		if strings.EqualFold(n, deviceName) {
+			in.Device = d
+			found = true
+			break
+		}
+	}
+	if !found {
+		return Input{}, fmt.Errorf("invalid input %q: unknown device %s", s, deviceName)
+	}
+
+	if in.Device == DeviceGamepadAxis || in.Device == DeviceMouseAxis {
+		switch {
+		case strings.HasSuffix(name, "+"):
+			in.Direction = 1
+			name = strings.TrimSuffix(name, "+")
+		case strings.HasSuffix(name, "-"):
+			in.Direction = -1
+			name = strings.TrimSuffix(name, "-")
+		}
+	}
+
+	if in.Device == DeviceKeyboard {
+		in.Mods, name = parseMods(name)
+		code, ok := keyCodes[strings.ToLower(name)]
+		if !ok {
+			return Input{}, fmt.Errorf("invalid input %q: unknown key %s", s, name)
+		}
+		in.Code = code
+		return in, nil
+	}
+
+	for code, n := range codeNames(in.Device) {
+		if strings.EqualFold(n, name) {
+			in.Code = code
+			return in, nil
+		}
+	}
+	return Input{}, fmt.Errorf("invalid input %q: unknown %s %s", s, in.Device, name)
+}
+
+// parseMods strips the modifier prefixes of a key name, such as "Ctrl+".
+func parseMods(name string) (window.ModifierKey, string) {
+	modNames := map[string]window.ModifierKey{
+		"ctrl+":  window.ModControl,
+		"shift+": window.ModShift,
+		"alt+":   window.ModAlt,
+		"super+": window.ModSuper,
+	}
+	var mods window.ModifierKey
+	for {
+		stripped := false
+		for prefix, mod := range modNames {
+			if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				mods |= mod
+				name = name[len(prefix):]
+				stripped = true
+			}
+		}
+		if !stripped {
+			return mods, name
+		}
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (i Input) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (i *Input) UnmarshalText(text []byte) error {
+	in, err := ParseInput(string(text))
+	if err != nil {
+		return err
+	}
+	*i = in
+	return nil
+}
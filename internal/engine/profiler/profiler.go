@@ -0,0 +1,311 @@
+// Package profiler measures named scopes on the CPU and the GPU,
+// aggregates them per frame and exports them as Chrome trace events.
+//
+// CPU scopes are measured using:
+//
+//	defer p.Scope("Quad.End")()
+//
+// A nil profiler records nothing, so that packages can be handed
+// an optional profiler.
+//
+// GPU scopes use GL_TIME_ELAPSED queries, whose results are read back
+// asynchronously a few frames later. GL only allows one such query to
+// be active at a time, so nested GPU scopes are measured on the CPU only.
+package profiler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
+
+var (
+	defaultHistoryFrames = 300
+)
+
+// Event is a measured scope.
+type Event struct {
+	Name  string
+	Frame int
+	GPU   bool
+	Depth int
+	// Start is relative to the profiler epoch.
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// ScopeStats aggregates the events of a scope within a frame.
+type ScopeStats struct {
+	Name  string
+	Count int
+	Total time.Duration
+}
+
+// FrameProfile aggregates the events of a frame.
+type FrameProfile struct {
+	Frame    int
+	Duration time.Duration
+	// CPU and GPU scopes, sorted by decreasing total time.
+	CPU []ScopeStats
+	GPU []ScopeStats
+}
+
+type gpuQuery struct {
+	id    uint32
+	event Event
+}
+
+// Profiler .
+type Profiler struct {
+	mu sync.Mutex
+
+	enabled bool
+	epoch   time.Time
+
+	frame      int
+	frameStart time.Time
+	depth      int
+
+	// events of the frames in the history
+	events        []Event
+	historyFrames int
+
+	gpuActive  bool
+	gpuPending []gpuQuery
+	gpuFree    []uint32
+
+	lastFrame FrameProfile
+}
+
+// New creates a disabled profiler.
+func New() *Profiler {
+	return &Profiler{
+		epoch:         time.Now(),
+		historyFrames: defaultHistoryFrames,
+	}
+}
+
+// SetEnabled .
+func (p *Profiler) SetEnabled(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = enabled
+}
+
+// Enabled .
+func (p *Profiler) Enabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enabled
+}
+
+// SetHistoryFrames sets the number of frames of events kept for export.
+func (p *Profiler) SetHistoryFrames(frames int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.historyFrames = frames
+}
+
+// Scope starts a CPU scope. The returned function ends it.
+func (p *Profiler) Scope(name string) func() {
+	if p == nil {
+		return func() {}
+	}
+	p.mu.Lock()
+	if !p.enabled {
+		p.mu.Unlock()
+		return func() {}
+	}
+	frame, depth := p.frame, p.depth
+	p.depth++
+	p.mu.Unlock()
+
+	start := time.Now()
+	return func() {
+		end := time.Now()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.depth--
+		p.events = append(p.events, Event{
+			Name:     name,
+			Frame:    frame,
+			Depth:    depth,
+			Start:    start.Sub(p.epoch),
+			Duration: end.Sub(start),
+		})
+	}
+}
+
+// GPUScope starts a CPU scope along with a GPU timer query when no
+// other GPU scope is active. The returned function ends both.
+// It must be called on the main thread.
+func (p *Profiler) GPUScope(name string) func() {
+	if p == nil {
+		return func() {}
+	}
+	endCPU := p.Scope(name)
+
+	p.mu.Lock()
+	if !p.enabled || p.gpuActive {
+		p.mu.Unlock()
+		return endCPU
+	}
+	p.gpuActive = true
+	event := Event{
+		Name:  name,
+		Frame: p.frame,
+		GPU:   true,
+		Depth: p.depth - 1,
+		Start: time.Since(p.epoch),
+	}
+	id := p.query()
+	p.mu.Unlock()
+
+	gl.BeginQuery(gl.TIME_ELAPSED, id)
+	return func() {
+		gl.EndQuery(gl.TIME_ELAPSED)
+		endCPU()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.gpuActive = false
+		p.gpuPending = append(p.gpuPending, gpuQuery{id: id, event: event})
+	}
+}
+
+func (p *Profiler) query() uint32 {
+	if n := len(p.gpuFree); n > 0 {
+		id := p.gpuFree[n-1]
+		p.gpuFree = p.gpuFree[:n-1]
+		return id
+	}
+	var id uint32
+	gl.GenQueries(1, &id)
+	return id
+}
+
+// BeginFrame starts a new frame. It must be called on the main thread.
+func (p *Profiler) BeginFrame() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.collectGPU()
+	p.frame++
+	p.frameStart = time.Now()
+	p.depth = 0
+}
+
+// EndFrame aggregates the events of the current frame
+// and trims the history.
+func (p *Profiler) EndFrame() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.enabled {
+		p.events = p.events[:0]
+		return
+	}
+	p.lastFrame = p.aggregate(p.frame, time.Since(p.frameStart))
+
+	// drop events of frames older than the history,
+	// GPU events are not ordered by frame as they arrive late
+	oldest := p.frame - p.historyFrames
+	events := p.events[:0]
+	for _, e := range p.events {
+		if e.Frame > oldest {
+			events = append(events, e)
+		}
+	}
+	p.events = events
+}
+
+// collectGPU reads the results of the available GPU queries.
+func (p *Profiler) collectGPU() {
+	pending := p.gpuPending[:0]
+	for _, q := range p.gpuPending {
+		var available int32
+		gl.GetQueryObjectiv(q.id, gl.QUERY_RESULT_AVAILABLE, &available)
+		if available == gl.FALSE {
+			pending = append(pending, q)
+			continue
+		}
+		var elapsed uint64
+		gl.GetQueryObjectui64v(q.id, gl.QUERY_RESULT, &elapsed)
+		p.gpuFree = append(p.gpuFree, q.id)
+
+		q.event.Duration = time.Duration(elapsed)
+		p.events = append(p.events, q.event)
+	}
+	p.gpuPending = pending
+}
+
+// aggregate sums the events of frame. GPU events of a frame are
+// available a few frames later, so the GPU stats are those of the
+// most recent frame having results.
+func (p *Profiler) aggregate(frame int, duration time.Duration) FrameProfile {
+	profile := FrameProfile{Frame: frame, Duration: duration}
+
+	gpuFrame := -1
+	for _, e := range p.events {
+		if e.GPU && e.Frame > gpuFrame {
+			gpuFrame = e.Frame
+		}
+	}
+
+	cpu := make(map[string]*ScopeStats)
+	gpu := make(map[string]*ScopeStats)
+	for _, e := range p.events {
+		stats := cpu
+		if e.GPU {
+			if e.Frame != gpuFrame {
+				continue
+			}
+			stats = gpu
+		} else if e.Frame != frame {
+			continue
+		}
+		s, ok := stats[e.Name]
+		if !ok {
+			s = &ScopeStats{Name: e.Name}
+			stats[e.Name] = s
+		}
+		s.Count++
+		s.Total += e.Duration
+	}
+	profile.CPU = sortedStats(cpu)
+	profile.GPU = sortedStats(gpu)
+	return profile
+}
+
+func sortedStats(m map[string]*ScopeStats) []ScopeStats {
+	stats := make([]ScopeStats, 0, len(m))
+	for _, s := range m {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total == stats[j].Total {
+			return stats[i].Name < stats[j].Name
+		}
+		return stats[i].Total > stats[j].Total
+	})
+	return stats
+}
+
+// LastFrame returns the profile of the last ended frame.
+func (p *Profiler) LastFrame() FrameProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastFrame
+}
+
+// Events returns a copy of the events in the history.
+func (p *Profiler) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := make([]Event, len(p.events))
+	copy(events, p.events)
+	return events
+}
@@ -0,0 +1,81 @@
+package profiler
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+)
+
+// trace thread ids of CPU and GPU events
+const (
+	traceTIDCPU = 1
+	traceTIDGPU = 2
+)
+
+// traceEvent is a complete event of the Chrome trace event format.
+// See https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp float64                `json:"ts"`
+	Duration  float64                `json:"dur,omitempty"`
+	PID       int                    `json:"pid"`
+	TID       int                    `json:"tid"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+type traceFile struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteChromeTrace writes the events in the history as Chrome trace
+// event JSON, viewable in chrome://tracing or Perfetto. GPU events
+// are placed at the time their query was issued on the CPU.
+func (p *Profiler) WriteChromeTrace(w io.Writer) error {
+	events := p.Events()
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start < events[j].Start
+	})
+
+	trace := traceFile{
+		DisplayTimeUnit: "ms",
+		TraceEvents: []traceEvent{
+			{Name: "thread_name", Phase: "M", PID: 1, TID: traceTIDCPU, Args: map[string]interface{}{"name": "CPU"}},
+			{Name: "thread_name", Phase: "M", PID: 1, TID: traceTIDGPU, Args: map[string]interface{}{"name": "GPU"}},
+		},
+	}
+	for _, e := range events {
+		te := traceEvent{
+			Name:      e.Name,
+			Category:  "cpu",
+			Phase:     "X",
+			Timestamp: float64(e.Start.Nanoseconds()) / 1000,
+			Duration:  float64(e.Duration.Nanoseconds()) / 1000,
+			PID:       1,
+			TID:       traceTIDCPU,
+			Args:      map[string]interface{}{"frame": e.Frame},
+		}
+		if e.GPU {
+			te.Category = "gpu"
+			te.TID = traceTIDGPU
+		}
+		trace.TraceEvents = append(trace.TraceEvents, te)
+	}
+	return json.NewEncoder(w).Encode(trace)
+}
+
+// SaveChromeTrace writes the Chrome trace to the file at path.
+func (p *Profiler) SaveChromeTrace(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteChromeTrace(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -3,6 +3,7 @@ package renderer
 import (
 	"math"
 
+	"github.com/devodev/opengl-experiment/internal/engine/input"
 	"github.com/devodev/opengl-experiment/internal/engine/window"
 	"github.com/go-gl/mathgl/mgl32"
 )
@@ -15,8 +16,40 @@ var (
 	defaultControllerRotationSensitivity = float32(100)
 	defaultControllerYaw                 = float32(-90.0)
 	defaultControllerPitch               = float32(0.0)
+	defaultControllerZoomSpeed           = float32(0.25)
+	defaultControllerStickLookSpeed      = float32(120)
 )
 
+// Camera controller input context, actions and axes
+const (
+	CameraInputContextName = "camera"
+
+	ActionCameraRotate = "camera_rotate"
+	AxisCameraMoveX    = "camera_move_x"
+	AxisCameraMoveY    = "camera_move_y"
+	AxisCameraLookX    = "camera_look_x"
+	AxisCameraLookY    = "camera_look_y"
+	AxisCameraZoom     = "camera_zoom"
+)
+
+// CameraInputContext returns the default bindings of the camera controller:
+// WASD and the left stick move, dragging the left mouse button and the
+// right stick rotate, and the scroll wheel zooms.
+func CameraInputContext() *input.Context {
+	// stick Y axes are positive downward
+	return input.NewContext(CameraInputContextName).
+		Bind(ActionCameraRotate, input.MouseButton(window.MouseButtonLeft)).
+		BindAxis(AxisCameraMoveX,
+			input.DigitalAxis(input.Key(window.KeyD, 0), input.Key(window.KeyA, 0)),
+			input.AnalogAxis(input.GamepadAxis(window.GamepadAxisLeftX, 0), 1)).
+		BindAxis(AxisCameraMoveY,
+			input.DigitalAxis(input.Key(window.KeyW, 0), input.Key(window.KeyS, 0)),
+			input.AnalogAxis(input.GamepadAxis(window.GamepadAxisLeftY, 0), -1)).
+		BindAxis(AxisCameraLookX, input.AnalogAxis(input.GamepadAxis(window.GamepadAxisRightX, 0), 1)).
+		BindAxis(AxisCameraLookY, input.AnalogAxis(input.GamepadAxis(window.GamepadAxisRightY, 0), -1)).
+		BindAxis(AxisCameraZoom, input.AnalogAxis(input.MouseAxisInput(input.MouseAxisScrollY), 1))
+}
+
 func sin(v float32) float32 {
 	return float32(math.Sin(float64(v)))
 
@@ -27,16 +60,21 @@ func cos(v float32) float32 {
 
 // CameraController .
 type CameraController struct {
-	pos                   mgl32.Vec3
-	target                mgl32.Vec3
-	up                    mgl32.Vec3
-	baseSpeed             float32
-	rotationSensitivity   float32
-	yaw                   float32
-	pitch                 float32
-	mousePosX             float64
-	mousePosY             float64
-	mouseButton1IsPressed bool
+	pos                 mgl32.Vec3
+	target              mgl32.Vec3
+	up                  mgl32.Vec3
+	baseSpeed           float32
+	rotationSensitivity float32
+	yaw                 float32
+	pitch               float32
+	zoomSpeed           float32
+	// stickLookSpeed is the rotation speed, in degrees per
+	// second, with the right stick fully pushed
+	stickLookSpeed float32
+	rotating       bool
+
+	actions     *input.ActionMap
+	ownsActions bool
 
 	viewMatrix mgl32.Mat4
 
@@ -45,19 +83,23 @@ type CameraController struct {
 
 // NewCameraController .
 func NewCameraController(camera Camera) *CameraController {
-	width, height := camera.ViewPortDimensions()
+	actions := input.NewActionMap()
+	actions.AddContext(CameraInputContext())
+	actions.PushContext(CameraInputContextName)
+
 	return &CameraController{
-		pos:                   defaultControllerPos,
-		target:                defaultControllerTarget,
-		up:                    defaultControllerUp,
-		baseSpeed:             defaultControllerBaseSpeed,
-		rotationSensitivity:   defaultControllerRotationSensitivity,
-		yaw:                   defaultControllerYaw,
-		pitch:                 defaultControllerPitch,
-		mousePosX:             float64(width / 2),
-		mousePosY:             float64(height / 2),
-		mouseButton1IsPressed: false,
-		camera:                camera,
+		pos:                 defaultControllerPos,
+		target:              defaultControllerTarget,
+		up:                  defaultControllerUp,
+		baseSpeed:           defaultControllerBaseSpeed,
+		rotationSensitivity: defaultControllerRotationSensitivity,
+		yaw:                 defaultControllerYaw,
+		pitch:               defaultControllerPitch,
+		zoomSpeed:           defaultControllerZoomSpeed,
+		stickLookSpeed:      defaultControllerStickLookSpeed,
+		actions:             actions,
+		ownsActions:         true,
+		camera:              camera,
 	}
 }
 
@@ -66,52 +108,62 @@ func (c *CameraController) OnUpdate(w *window.Window, deltaTime float64) {
 	if !w.IsFocused() {
 		return
 	}
+	if c.ownsActions {
+		c.actions.Update(w.Input())
+	}
 
 	// speed
 	speed := c.baseSpeed * float32(deltaTime)
 	// position
-	if w.IsKeyPressed(window.KeyW) {
-		c.moveForward(speed)
-	}
-	if w.IsKeyPressed(window.KeyS) {
-		c.moveBackward(speed)
-	}
-	if w.IsKeyPressed(window.KeyA) {
-		c.moveLeft(speed)
+	c.moveForward(c.actions.Axis(AxisCameraMoveY) * speed)
+	c.moveRight(c.actions.Axis(AxisCameraMoveX) * speed)
+	// zoom
+	if zoom := c.actions.Axis(AxisCameraZoom); zoom != 0 {
+		c.moveForward(zoom * c.zoomSpeed)
 	}
-	if w.IsKeyPressed(window.KeyD) {
-		c.moveRight(speed)
-	}
-	// rotation
-	if w.IsMouseButtonPressed(window.MouseButton1) {
-		cursorX, cursorY := w.GetCursorPos()
-		if !c.mouseButton1IsPressed {
-			c.mouseButton1IsPressed = true
-			c.mousePosX = cursorX
-			c.mousePosY = cursorY
+	// rotation, the cursor is captured while dragging
+	if c.actions.Pressed(ActionCameraRotate) {
+		if !c.rotating {
+			c.rotating = true
+			w.SetCursorMode(window.CursorDisabled)
+			w.SetRawMouseMotion(true)
 		}
-		c.rotate(speed, cursorX, cursorY)
-	} else {
-		c.mouseButton1IsPressed = false
+		deltaX, deltaY := w.GetCursorDelta()
+		c.rotate(speed, deltaX, deltaY)
+	} else if c.rotating {
+		c.rotating = false
+		w.SetCursorMode(window.CursorNormal)
+	}
+	// rotation using axes, such as a gamepad stick
+	lookX := c.actions.Axis(AxisCameraLookX)
+	lookY := c.actions.Axis(AxisCameraLookY)
+	if lookX != 0 || lookY != 0 {
+		c.yaw += lookX * c.stickLookSpeed * float32(deltaTime)
+		c.pitch += lookY * c.stickLookSpeed * float32(deltaTime)
+		c.recalculateTarget()
 	}
 
 	c.camera.Resize(w.GetSize())
 	c.recalculateViewMatrix()
 }
 
+// SetActionMap makes the controller query its actions and axes from m,
+// which must be updated every frame and have the camera context, as
+// returned by CameraInputContext, on its stack. By default, the
+// controller updates a map of its own using the default bindings.
+func (c *CameraController) SetActionMap(m *input.ActionMap) {
+	c.actions = m
+	c.ownsActions = false
+}
+
 // GetViewProjectionMatrix .
 func (c *CameraController) GetViewProjectionMatrix() mgl32.Mat4 {
 	return c.camera.ProjectionMatrix().Mul4(c.viewMatrix)
 }
 
-func (c *CameraController) rotate(speed float32, posX, posY float64) {
-	xOffset := posX - c.mousePosX
-	yOffset := c.mousePosY - posY
-	c.mousePosX = posX
-	c.mousePosY = posY
-
-	c.yaw -= float32(xOffset) * c.rotationSensitivity * speed
-	c.pitch -= float32(yOffset) * c.rotationSensitivity * speed
+func (c *CameraController) rotate(speed float32, deltaX, deltaY float64) {
+	c.yaw -= float32(deltaX) * c.rotationSensitivity * speed
+	c.pitch += float32(deltaY) * c.rotationSensitivity * speed
 
 	c.recalculateTarget()
 }
@@ -120,14 +172,6 @@ func (c *CameraController) moveForward(speed float32) {
 	c.pos = c.pos.Add(c.target.Mul(speed))
 }
 
-func (c *CameraController) moveBackward(speed float32) {
-	c.pos = c.pos.Sub(c.target.Mul(speed))
-}
-
-func (c *CameraController) moveLeft(speed float32) {
-	c.pos = c.pos.Sub(c.target.Normalize().Cross(c.up).Mul(speed))
-}
-
 func (c *CameraController) moveRight(speed float32) {
 	c.pos = c.pos.Add(c.target.Normalize().Cross(c.up).Mul(speed))
 }
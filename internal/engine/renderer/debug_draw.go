@@ -0,0 +1,201 @@
+package renderer
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	defaultDebugDrawThickness = float32(0.005)
+
+	debugDrawAxisColors = [3]mgl32.Vec4{
+		{1, 0, 0, 1},
+		{0, 1, 0, 1},
+		{0, 0, 1, 1},
+	}
+)
+
+// debugPrimitive is a shape that stays on screen
+// until its remaining duration has elapsed.
+type debugPrimitive struct {
+	remaining float64
+	draw      func(*Shape)
+}
+
+// DebugDraw collects transient world-space gizmos.
+// Primitives can be added at any time during a frame and are
+// rendered on top of everything else using the view projection
+// matrix of the last scene. A duration of 0 renders a primitive
+// for a single frame.
+type DebugDraw struct {
+	enabled   bool
+	thickness float32
+
+	primitives []debugPrimitive
+}
+
+// NewDebugDraw .
+func NewDebugDraw() *DebugDraw {
+	return &DebugDraw{
+		enabled:   true,
+		thickness: defaultDebugDrawThickness,
+	}
+}
+
+// Enabled .
+func (d *DebugDraw) Enabled() bool {
+	return d.enabled
+}
+
+// SetEnabled .
+func (d *DebugDraw) SetEnabled(enabled bool) {
+	d.enabled = enabled
+}
+
+// Toggle .
+func (d *DebugDraw) Toggle() {
+	d.enabled = !d.enabled
+}
+
+// SetThickness sets the thickness (in world units) of lines added afterwards.
+func (d *DebugDraw) SetThickness(thickness float32) {
+	d.thickness = thickness
+}
+
+// Clear removes all primitives.
+func (d *DebugDraw) Clear() {
+	d.primitives = d.primitives[:0]
+}
+
+func (d *DebugDraw) add(duration float64, draw func(*Shape)) {
+	d.primitives = append(d.primitives, debugPrimitive{remaining: duration, draw: draw})
+}
+
+// DrawLine .
+func (d *DebugDraw) DrawLine(a, b mgl32.Vec3, color mgl32.Vec4, duration float64) {
+	thickness := d.thickness
+	d.add(duration, func(s *Shape) {
+		s.AddLine(a, b, thickness, color)
+	})
+}
+
+// DrawArrow .
+func (d *DebugDraw) DrawArrow(from, to mgl32.Vec3, color mgl32.Vec4, duration float64) {
+	thickness := d.thickness
+	d.add(duration, func(s *Shape) {
+		s.AddArrow(from, to, thickness, thickness*10, color)
+	})
+}
+
+// DrawCircle draws a ring of the provided radius centered on center.
+// Nothing is drawn when the radius is not positive.
+func (d *DebugDraw) DrawCircle(center mgl32.Vec3, radius float32, color mgl32.Vec4, duration float64) {
+	if radius <= 0 {
+		return
+	}
+	thickness := d.thickness / radius
+	transform := mgl32.Translate3D(center.X(), center.Y(), center.Z()).Mul4(mgl32.Scale3D(radius*2, radius*2, 1))
+	d.add(duration, func(s *Shape) {
+		s.AddCircle(transform, thickness, defaultCircleFade, color)
+	})
+}
+
+// DrawAABB draws the 12 edges of the axis-aligned bounding box
+// defined by its min and max corners.
+func (d *DebugDraw) DrawAABB(min, max mgl32.Vec3, color mgl32.Vec4, duration float64) {
+	corners := [8]mgl32.Vec3{
+		{min.X(), min.Y(), min.Z()},
+		{max.X(), min.Y(), min.Z()},
+		{max.X(), max.Y(), min.Z()},
+		{min.X(), max.Y(), min.Z()},
+		{min.X(), min.Y(), max.Z()},
+		{max.X(), min.Y(), max.Z()},
+		{max.X(), max.Y(), max.Z()},
+		{min.X(), max.Y(), max.Z()},
+	}
+	edges := [12][2]int{
+		{0, 1}, {1, 2}, {2, 3}, {3, 0},
+		{4, 5}, {5, 6}, {6, 7}, {7, 4},
+		{0, 4}, {1, 5}, {2, 6}, {3, 7},
+	}
+	thickness := d.thickness
+	d.add(duration, func(s *Shape) {
+		for _, e := range edges {
+			s.AddLine(corners[e[0]], corners[e[1]], thickness, color)
+		}
+	})
+}
+
+// DrawAxis draws the X (red), Y (green) and Z (blue) axes
+// of transform as arrows of the provided length.
+func (d *DebugDraw) DrawAxis(transform mgl32.Mat4, length float32, duration float64) {
+	origin := transform.Mul4x1(mgl32.Vec4{0, 0, 0, 1}).Vec3()
+	axes := [3]mgl32.Vec3{
+		transform.Mul4x1(mgl32.Vec4{length, 0, 0, 1}).Vec3(),
+		transform.Mul4x1(mgl32.Vec4{0, length, 0, 1}).Vec3(),
+		transform.Mul4x1(mgl32.Vec4{0, 0, length, 1}).Vec3(),
+	}
+	thickness := d.thickness
+	d.add(duration, func(s *Shape) {
+		for i, axis := range axes {
+			s.AddArrow(origin, axis, thickness, thickness*10, debugDrawAxisColors[i])
+		}
+	})
+}
+
+// DrawGrid draws a grid of cells*cells unit squares in the XY plane
+// of transform, centered on its origin.
+func (d *DebugDraw) DrawGrid(transform mgl32.Mat4, cells int, color mgl32.Vec4, duration float64) {
+	if cells <= 0 {
+		return
+	}
+	half := float32(cells) / 2
+	points := make([][2]mgl32.Vec3, 0, 2*(cells+1))
+	for i := 0; i <= cells; i++ {
+		offset := float32(i) - half
+		points = append(points,
+			[2]mgl32.Vec3{
+				transform.Mul4x1(mgl32.Vec4{offset, -half, 0, 1}).Vec3(),
+				transform.Mul4x1(mgl32.Vec4{offset, half, 0, 1}).Vec3(),
+			},
+			[2]mgl32.Vec3{
+				transform.Mul4x1(mgl32.Vec4{-half, offset, 0, 1}).Vec3(),
+				transform.Mul4x1(mgl32.Vec4{half, offset, 0, 1}).Vec3(),
+			},
+		)
+	}
+	thickness := d.thickness
+	d.add(duration, func(s *Shape) {
+		for _, p := range points {
+			s.AddLine(p[0], p[1], thickness, color)
+		}
+	})
+}
+
+// Update removes the primitives whose duration has elapsed.
+func (d *DebugDraw) Update(deltaTime float64) {
+	alive := d.primitives[:0]
+	for _, p := range d.primitives {
+		p.remaining -= deltaTime
+		if p.remaining > 0 {
+			alive = append(alive, p)
+		}
+	}
+	// release references held by expired primitives
+	for i := len(alive); i < len(d.primitives); i++ {
+		d.primitives[i] = debugPrimitive{}
+	}
+	d.primitives = alive
+}
+
+// Render draws all primitives on top of the current framebuffer
+// using the view projection matrix of the last scene.
+func (d *DebugDraw) Render(r *Renderer) {
+	if !d.enabled || len(d.primitives) == 0 {
+		return
+	}
+	r.shapeProgram.Begin(r.viewProjection)
+	for _, p := range d.primitives {
+		p.draw(r.shapeProgram)
+	}
+	r.shapeProgram.End()
+}
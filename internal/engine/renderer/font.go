@@ -0,0 +1,381 @@
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+
+	"github.com/devodev/opengl-experiment/internal/opengl"
+	"github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+var (
+	defaultFontPixelSize = float32(48)
+	defaultFontSDFSpread = 8
+
+	fontAtlasSize    = 1024
+	fontGlyphPadding = 2
+
+	// runes rasterized when creating a font (printable ASCII and Latin-1),
+	// others are added on demand
+	fontPreloadedRunes = [][2]rune{
+		{0x20, 0x7e},
+		{0xa0, 0xff},
+	}
+	fontReplacementRunes = []rune{'�', '?'}
+)
+
+// fontGlyph describes a glyph stored in the font atlas.
+// Bounds are expressed in pixels relative to the
+// glyph origin on the baseline, with Y pointing down.
+type fontGlyph struct {
+	texCoords [4]mgl32.Vec2
+	bounds    image.Rectangle
+	advance   float32
+	visible   bool
+}
+
+// Font is a TrueType font rasterized into a glyph atlas texture.
+// Glyphs missing from the atlas are rasterized the first time they are drawn.
+type Font struct {
+	font       *sfnt.Font
+	buf        sfnt.Buffer
+	ppem       fixed.Int26_6
+	pixelSize  float32
+	ascent     float32
+	descent    float32
+	lineHeight float32
+
+	// when sdf is true, the atlas stores signed distance fields
+	// spreading sdfSpread pixels around each glyph
+	sdf       bool
+	sdfSpread int
+
+	glyphs map[rune]*fontGlyph
+
+	// atlas packing state (shelf packing)
+	atlas       *image.NRGBA
+	atlasDirty  bool
+	penX        int
+	penY        int
+	shelfHeight int
+
+	texture opengl.MutableTexture
+}
+
+// NewFont parses ttf and rasterizes it at pixelSize into a glyph atlas.
+// Larger pixel sizes produce crisper text at the cost of atlas space.
+func NewFont(ttf []byte, pixelSize float32) (*Font, error) {
+	return newFont(ttf, pixelSize, false)
+}
+
+// NewSDFFont parses ttf and stores signed distance fields of its glyphs,
+// computed at pixelSize, into a glyph atlas. SDF fonts stay crisp at any
+// scale and support outlines and drop shadows.
+func NewSDFFont(ttf []byte, pixelSize float32) (*Font, error) {
+	return newFont(ttf, pixelSize, true)
+}
+
+func newFont(ttf []byte, pixelSize float32, sdf bool) (*Font, error) {
+	parsed, err := sfnt.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing font: %s", err)
+	}
+	ppem := fixed.Int26_6(pixelSize * 64)
+
+	var buf sfnt.Buffer
+	metrics, err := parsed.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("error reading font metrics: %s", err)
+	}
+
+	f := &Font{
+		font:       parsed,
+		ppem:       ppem,
+		pixelSize:  pixelSize,
+		ascent:     fixedToFloat(metrics.Ascent),
+		descent:    fixedToFloat(metrics.Descent),
+		lineHeight: fixedToFloat(metrics.Height),
+		sdf:        sdf,
+		sdfSpread:  defaultFontSDFSpread,
+		glyphs:     make(map[rune]*fontGlyph),
+		atlas:      image.NewNRGBA(image.Rect(0, 0, fontAtlasSize, fontAtlasSize)),
+	}
+
+	for _, runes := range fontPreloadedRunes {
+		for r := runes[0]; r <= runes[1]; r++ {
+			f.addGlyph(r)
+		}
+	}
+	for _, r := range fontReplacementRunes {
+		f.addGlyph(r)
+	}
+
+	texture, err := opengl.NewNRGBATextureFromImage(f.atlas)
+	if err != nil {
+		return nil, fmt.Errorf("error creating font atlas texture: %s", err)
+	}
+	f.texture = texture
+	f.atlasDirty = false
+
+	return f, nil
+}
+
+// LoadFont reads a TrueType font file and creates a Font from it.
+func LoadFont(filepath string, pixelSize float32) (*Font, error) {
+	ttf, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading font file: %s", err)
+	}
+	return NewFont(ttf, pixelSize)
+}
+
+// LoadSDFFont reads a TrueType font file and creates an SDF Font from it.
+func LoadSDFFont(filepath string, pixelSize float32) (*Font, error) {
+	ttf, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading font file: %s", err)
+	}
+	return NewSDFFont(ttf, pixelSize)
+}
+
+// NewDefaultFont creates a Font using the Go Regular typeface.
+func NewDefaultFont() (*Font, error) {
+	return NewFont(goregular.TTF, defaultFontPixelSize)
+}
+
+// NewDefaultSDFFont creates an SDF Font using the Go Regular typeface.
+func NewDefaultSDFFont() (*Font, error) {
+	return NewSDFFont(goregular.TTF, defaultFontPixelSize)
+}
+
+// Delete frees the atlas texture. The font must not be used afterwards.
+func (f *Font) Delete() {
+	if d, ok := f.texture.(opengl.Deleter); ok {
+		d.Delete()
+	}
+}
+
+// IsSDF reports whether the atlas stores signed distance fields.
+func (f *Font) IsSDF() bool {
+	return f.sdf
+}
+
+// Texture returns the atlas texture.
+func (f *Font) Texture() opengl.Texture {
+	return f.texture
+}
+
+// LineHeight returns the distance between two baselines,
+// relative to a font size of 1.
+func (f *Font) LineHeight() float32 {
+	return f.lineHeight / f.pixelSize
+}
+
+// Measure returns the width and height of str when drawn at size.
+func (f *Font) Measure(str string, size float32) (float32, float32) {
+	var width float32
+	lines := f.layoutLines(str)
+	for _, l := range lines {
+		if l.width > width {
+			width = l.width
+		}
+	}
+	scale := size / f.pixelSize
+	height := f.ascent + f.descent + float32(len(lines)-1)*f.lineHeight
+	return width * scale, height * scale
+}
+
+// glyph returns the atlas glyph of r, rasterizing it when missing.
+func (f *Font) glyph(r rune) *fontGlyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	if g := f.addGlyph(r); g != nil {
+		return g
+	}
+	for _, replacement := range fontReplacementRunes {
+		if g, ok := f.glyphs[replacement]; ok {
+			return g
+		}
+	}
+	return nil
+}
+
+func (f *Font) addGlyph(r rune) *fontGlyph {
+	index, err := f.font.GlyphIndex(&f.buf, r)
+	if err != nil || index == 0 {
+		return nil
+	}
+	advance, err := f.font.GlyphAdvance(&f.buf, index, f.ppem, font.HintingNone)
+	if err != nil {
+		return nil
+	}
+	dr, mask, err := f.rasterize(index)
+	if err != nil {
+		return nil
+	}
+
+	g := &fontGlyph{
+		bounds:  dr,
+		advance: fixedToFloat(advance),
+		visible: !dr.Empty(),
+	}
+	if g.visible {
+		var src image.Image = mask
+		if f.sdf {
+			src = signedDistanceField(mask, mask.Bounds(), image.Point{}, f.sdfSpread)
+			g.bounds = dr.Inset(-f.sdfSpread)
+		}
+		rect, ok := f.allocate(g.bounds.Dx(), g.bounds.Dy())
+		if !ok {
+			return nil
+		}
+		draw.DrawMask(f.atlas, rect, image.White, image.Point{}, src, image.Point{}, draw.Src)
+		g.texCoords = atlasTexCoords(rect, fontAtlasSize)
+		f.atlasDirty = true
+	}
+	f.glyphs[r] = g
+	return g
+}
+
+// rasterize renders the outline of a glyph into a coverage mask.
+// The returned rectangle is the position of the mask relative to
+// the glyph origin on the baseline, with Y pointing down.
+func (f *Font) rasterize(index sfnt.GlyphIndex) (image.Rectangle, *image.Alpha, error) {
+	segments, err := f.font.LoadGlyph(&f.buf, index, f.ppem, nil)
+	if err != nil {
+		return image.Rectangle{}, nil, err
+	}
+	if len(segments) == 0 {
+		return image.Rectangle{}, nil, nil
+	}
+
+	// compute the pixel bounds of the outline
+	bounds := fixed.Rectangle26_6{
+		Min: fixed.Point26_6{X: segments[0].Args[0].X, Y: segments[0].Args[0].Y},
+		Max: fixed.Point26_6{X: segments[0].Args[0].X, Y: segments[0].Args[0].Y},
+	}
+	for _, seg := range segments {
+		for _, arg := range seg.Args[:segmentArgCount(seg.Op)] {
+			bounds = bounds.Union(fixed.Rectangle26_6{Min: arg, Max: arg.Add(fixed.Point26_6{X: 1, Y: 1})})
+		}
+	}
+	dr := image.Rect(bounds.Min.X.Floor(), bounds.Min.Y.Floor(), bounds.Max.X.Ceil(), bounds.Max.Y.Ceil())
+	if dr.Empty() {
+		return image.Rectangle{}, nil, nil
+	}
+
+	originX := float32(dr.Min.X)
+	originY := float32(dr.Min.Y)
+	point := func(p fixed.Point26_6) (float32, float32) {
+		return fixedToFloat(p.X) - originX, fixedToFloat(p.Y) - originY
+	}
+
+	rasterizer := vector.NewRasterizer(dr.Dx(), dr.Dy())
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			rasterizer.MoveTo(point(seg.Args[0]))
+		case sfnt.SegmentOpLineTo:
+			rasterizer.LineTo(point(seg.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			bx, by := point(seg.Args[0])
+			cx, cy := point(seg.Args[1])
+			rasterizer.QuadTo(bx, by, cx, cy)
+		case sfnt.SegmentOpCubeTo:
+			bx, by := point(seg.Args[0])
+			cx, cy := point(seg.Args[1])
+			dx, dy := point(seg.Args[2])
+			rasterizer.CubeTo(bx, by, cx, cy, dx, dy)
+		}
+	}
+	rasterizer.ClosePath()
+
+	mask := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	rasterizer.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	return dr, mask, nil
+}
+
+// kern returns the horizontal adjustment, in pixels,
+// between two consecutive runes.
+func (f *Font) kern(r0, r1 rune) float32 {
+	i0, err := f.font.GlyphIndex(&f.buf, r0)
+	if err != nil {
+		return 0
+	}
+	i1, err := f.font.GlyphIndex(&f.buf, r1)
+	if err != nil {
+		return 0
+	}
+	k, err := f.font.Kern(&f.buf, i0, i1, f.ppem, font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	return fixedToFloat(k)
+}
+
+func segmentArgCount(op sfnt.SegmentOp) int {
+	switch op {
+	case sfnt.SegmentOpQuadTo:
+		return 2
+	case sfnt.SegmentOpCubeTo:
+		return 3
+	}
+	return 1
+}
+
+// allocate reserves a region of the atlas using shelf packing.
+func (f *Font) allocate(width, height int) (image.Rectangle, bool) {
+	width += fontGlyphPadding
+	height += fontGlyphPadding
+	if f.penX+width > fontAtlasSize {
+		f.penX = 0
+		f.penY += f.shelfHeight
+		f.shelfHeight = 0
+	}
+	if f.penY+height > fontAtlasSize {
+		return image.Rectangle{}, false
+	}
+	rect := image.Rect(f.penX, f.penY, f.penX+width-fontGlyphPadding, f.penY+height-fontGlyphPadding)
+	f.penX += width
+	if height > f.shelfHeight {
+		f.shelfHeight = height
+	}
+	return rect, true
+}
+
+// upload sends the atlas to the GPU when glyphs were added since the last upload.
+func (f *Font) upload() {
+	if !f.atlasDirty {
+		return
+	}
+	f.texture.SetNRGBA(f.atlas)
+	f.atlasDirty = false
+}
+
+// atlasTexCoords returns the texture coordinates of rect matching
+// the vertex order of quadVertices. The atlas is uploaded without
+// flipping, so the first row of the image maps to v=0.
+func atlasTexCoords(rect image.Rectangle, size int) [4]mgl32.Vec2 {
+	u0 := float32(rect.Min.X) / float32(size)
+	v0 := float32(rect.Min.Y) / float32(size)
+	u1 := float32(rect.Max.X) / float32(size)
+	v1 := float32(rect.Max.Y) / float32(size)
+	return [4]mgl32.Vec2{
+		{u0, v0},
+		{u0, v1},
+		{u1, v1},
+		{u1, v0},
+	}
+}
+
+func fixedToFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
@@ -3,6 +3,7 @@ package renderer
 import (
 	"unsafe"
 
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
 	"github.com/devodev/opengl-experiment/internal/opengl"
 	"github.com/go-gl/gl/v4.6-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -33,7 +34,10 @@ var (
 		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
 		opengl.VBOLayoutElement{Count: 2, Normalized: false, DataType: opengl.GLDataTypeFloat},
 		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
 	)
+
+	quadDefaultColor = mgl32.Vec4{1, 1, 1, 1}
 )
 
 type TexturedQuad struct {
@@ -48,6 +52,9 @@ type Quad struct {
 	shaderProgram *opengl.ShaderProgram
 	// quad-related batch rendering data
 	data *quadData
+
+	stats    *rendererStats
+	profiler *profiler.Profiler
 }
 
 func (q *Quad) Init() error {
@@ -95,13 +102,24 @@ func (q *Quad) setViewProjectionMatrix(vp mgl32.Mat4) {
 
 func (q *Quad) Begin(vp mgl32.Mat4) {
 	// reset data each frame
-	q.data = newQuadData()
+	q.data.reset()
 	q.setViewProjectionMatrix(vp)
 }
 
 func (q *Quad) End() {
+	defer q.profiler.Scope("Quad.End")()
+
+	q.flush()
+}
+
+func (q *Quad) flush() {
+	if len(q.data.Indices) == 0 {
+		return
+	}
+
 	q.vbo.SetData(q.data)
 	q.vao.IBO().SetData(q.data)
+	q.stats.drawCall(len(q.data.Indices)/6, len(q.data.Vertices), len(q.data.Indices), len(q.data.Textures), q.data.VBOSize()+4*int(q.data.IBOCount()))
 
 	for _, t := range q.data.Textures {
 		t.Bind()
@@ -118,17 +136,35 @@ func (q *Quad) End() {
 	}
 	q.vao.Unbind()
 	q.shaderProgram.Unbind()
+
+	q.data.reset()
 }
 
 func (q *Quad) AddTextured(quad *TexturedQuad) error {
+	q.reserve()
 	return q.data.AddTextured(quad)
 }
 
+// addTexturedRegion adds a quad sampling a region of texture,
+// tinted using color.
+func (q *Quad) addTexturedRegion(transform mgl32.Mat4, texture opengl.Texture, texCoords [4]mgl32.Vec2, color mgl32.Vec4) error {
+	q.reserve()
+	return q.data.addQuad(transform, texture, texCoords, color)
+}
+
+// reserve flushes the current batch when it is full.
+func (q *Quad) reserve() {
+	if len(q.data.Vertices)+len(quadVertices) > maxVertices {
+		q.flush()
+	}
+}
+
 // QuadVertex .
 type QuadVertex struct {
 	Position mgl32.Vec4
 	TexCoord mgl32.Vec2
 	TexIndex float32
+	Color    mgl32.Vec4
 }
 
 // quadData .
@@ -146,9 +182,21 @@ func newQuadData() *quadData {
 	}
 }
 
+func (d *quadData) reset() {
+	d.Textures = make(map[int]opengl.Texture)
+	d.Vertices = d.Vertices[:0]
+	d.Indices = d.Indices[:0]
+}
+
 // AddTextured .
 func (d *quadData) AddTextured(quad *TexturedQuad) error {
-	if err := d.addTexture(quad.Texture); err != nil {
+	texCoords := [4]mgl32.Vec2{}
+	copy(texCoords[:], quadTexCoords)
+	return d.addQuad(quad.Transform, quad.Texture, texCoords, quadDefaultColor)
+}
+
+func (d *quadData) addQuad(transform mgl32.Mat4, texture opengl.Texture, texCoords [4]mgl32.Vec2, color mgl32.Vec4) error {
+	if err := d.addTexture(texture); err != nil {
 		return err
 	}
 
@@ -166,9 +214,10 @@ func (d *quadData) AddTextured(quad *TexturedQuad) error {
 	// add vertices
 	for i := 0; i < len(quadVertices); i++ {
 		vertex := QuadVertex{
-			Position: quad.Transform.Mul4x1(quadVertices[i]),
-			TexCoord: quadTexCoords[i],
-			TexIndex: float32(quad.Texture.Index()),
+			Position: transform.Mul4x1(quadVertices[i]),
+			TexCoord: texCoords[i],
+			TexIndex: float32(texture.Index()),
+			Color:    color,
 		}
 		d.Vertices = append(d.Vertices, vertex)
 	}
@@ -6,48 +6,52 @@ const (
 layout (location = 0) in vec4 position;
 layout (location = 1) in vec2 texCoord;
 layout (location = 2) in float texIndex;
+layout (location = 3) in vec4 color;
 
 out vec2 fragTexCoord;
 out float fragTexIndex;
+out vec4 fragColor;
 
 uniform mat4 vp;
 
 void main() {
     fragTexCoord = texCoord;
     fragTexIndex = texIndex;
+    fragColor = color;
     gl_Position = vp * position;
 }
     `
 
 	quadFragmentShader = `
 #version 460 core
-layout (location = 0) out vec4 fragColor;
+layout (location = 0) out vec4 outColor;
 
 in vec2 fragTexCoord;
 flat in float fragTexIndex;
+in vec4 fragColor;
 
 uniform sampler2D tex[32];
 
 void main() {
     // switch(int(fragTexIndex)) {
-    //     case 0: fragColor = texture(tex[0], fragTexCoord); break;
-    //     case 1: fragColor = texture(tex[1], fragTexCoord); break;
-    //     case 2: fragColor = texture(tex[2], fragTexCoord); break;
-    //     case 3: fragColor = texture(tex[3], fragTexCoord); break;
-    //     case 4: fragColor = texture(tex[4], fragTexCoord); break;
-    //     case 5: fragColor = texture(tex[5], fragTexCoord); break;
-    //     case 6: fragColor = texture(tex[6], fragTexCoord); break;
-    //     case 7: fragColor = texture(tex[7], fragTexCoord); break;
-    //     case 8: fragColor = texture(tex[8], fragTexCoord); break;
-    //     case 9: fragColor = texture(tex[9], fragTexCoord); break;
-    //     case 10: fragColor = texture(tex[10], fragTexCoord); break;
-    //     case 11: fragColor = texture(tex[11], fragTexCoord); break;
-    //     case 12: fragColor = texture(tex[12], fragTexCoord); break;
-    //     case 13: fragColor = texture(tex[13], fragTexCoord); break;
+    //     case 0: outColor = texture(tex[0], fragTexCoord); break;
+    //     case 1: outColor = texture(tex[1], fragTexCoord); break;
+    //     case 2: outColor = texture(tex[2], fragTexCoord); break;
+    //     case 3: outColor = texture(tex[3], fragTexCoord); break;
+    //     case 4: outColor = texture(tex[4], fragTexCoord); break;
+    //     case 5: outColor = texture(tex[5], fragTexCoord); break;
+    //     case 6: outColor = texture(tex[6], fragTexCoord); break;
+    //     case 7: outColor = texture(tex[7], fragTexCoord); break;
+    //     case 8: outColor = texture(tex[8], fragTexCoord); break;
+    //     case 9: outColor = texture(tex[9], fragTexCoord); break;
+    //     case 10: outColor = texture(tex[10], fragTexCoord); break;
+    //     case 11: outColor = texture(tex[11], fragTexCoord); break;
+    //     case 12: outColor = texture(tex[12], fragTexCoord); break;
+    //     case 13: outColor = texture(tex[13], fragTexCoord); break;
     // }
-    fragColor = texture(tex[int(fragTexIndex)], fragTexCoord);
-    //fragColor = texture(tex[15], fragTexCoord);
-    //fragColor = vec4(1,1,1,1);
+    outColor = texture(tex[int(fragTexIndex)], fragTexCoord) * fragColor;
+    //outColor = texture(tex[15], fragTexCoord);
+    //outColor = vec4(1,1,1,1);
 }
     `
 )
@@ -2,13 +2,16 @@ package renderer
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"os"
 	"strings"
 	"unsafe"
 
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
 	"github.com/devodev/opengl-experiment/internal/opengl"
 	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 var (
@@ -25,19 +28,41 @@ func min(a, b int) int {
 // Renderer .
 type Renderer struct {
 	bgColor color.RGBA
+	// view projection matrix of the last scene
+	viewProjection mgl32.Mat4
 
-	quadProgram *Quad
+	quadProgram    *Quad
+	shapeProgram   *Shape
+	textSDFProgram *TextSDF
+
+	// font used by DrawText, created on first use when not set
+	font *Font
+
+	stats    rendererStats
+	profiler *profiler.Profiler
 }
 
 // New .
 func New() (*Renderer, error) {
 	r := &Renderer{
-		bgColor:     defaultBackgroundColor,
-		quadProgram: &Quad{},
+		bgColor:        defaultBackgroundColor,
+		viewProjection: mgl32.Ident4(),
 	}
+	r.quadProgram = &Quad{stats: &r.stats}
+	r.shapeProgram = &Shape{stats: &r.stats}
+	r.textSDFProgram = &TextSDF{stats: &r.stats}
 	return r, nil
 }
 
+// SetProfiler sets the profiler recording the scopes of the
+// renderer. A nil profiler disables them.
+func (r *Renderer) SetProfiler(p *profiler.Profiler) {
+	r.profiler = p
+	r.quadProgram.profiler = p
+	r.shapeProgram.profiler = p
+	r.textSDFProgram.profiler = p
+}
+
 // Init .
 func (r *Renderer) Init() error {
 	// initialize OpenGL
@@ -57,6 +82,16 @@ func (r *Renderer) Init() error {
 		return err
 	}
 
+	// initialize shape-related rendering primitives
+	if err := r.shapeProgram.Init(); err != nil {
+		return err
+	}
+
+	// initialize sdf text-related rendering primitives
+	if err := r.textSDFProgram.Init(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -72,15 +107,83 @@ func (r *Renderer) Clear() {
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 }
 
-// BeginQuad .
-func (r *Renderer) BeginQuad(cameraController *CameraController) {
+// BeginScene starts batching quads and shapes using
+// the view projection matrix of the camera controller.
+func (r *Renderer) BeginScene(cameraController *CameraController) {
 	vp := cameraController.GetViewProjectionMatrix()
+	r.viewProjection = vp
 	r.quadProgram.Begin(vp)
+	r.shapeProgram.Begin(vp)
+	r.textSDFProgram.Begin(vp)
 }
 
-// EndQuad .
-func (r *Renderer) EndQuad() {
+// EndScene flushes the quads, shapes and SDF text batched since BeginScene.
+// Shapes are drawn on top of quads and SDF text on top of shapes.
+func (r *Renderer) EndScene() {
 	r.quadProgram.End()
+	r.shapeProgram.End()
+	r.textSDFProgram.End()
+}
+
+// Screenshot reads the content of the back buffer, within the current viewport.
+// It must be called after rendering and before swapping buffers.
+func (r *Renderer) Screenshot() *image.NRGBA {
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	width, height := int(viewport[2]), int(viewport[3])
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return img
+	}
+	gl.ReadBuffer(gl.BACK)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(viewport[0], viewport[1], viewport[2], viewport[3], gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+
+	// OpenGL rows start at the bottom, flip them in place
+	stride := img.Stride
+	row := make([]byte, stride)
+	for y := 0; y < height/2; y++ {
+		top := img.Pix[y*stride : (y+1)*stride]
+		bottom := img.Pix[(height-1-y)*stride : (height-y)*stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+
+	// the framebuffer alpha is not meaningful once presented
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// Stats returns the counters of the last completed frame.
+// It is safe to call from any goroutine.
+func (r *Renderer) Stats() Stats {
+	return r.stats.lastFrame()
+}
+
+// AddStats adds the counters of draws made outside of the renderer,
+// such as the GUI pass, to the current frame.
+func (r *Renderer) AddStats(s Stats) {
+	r.stats.add(s)
+}
+
+// ResetStats ends the current frame for statistics purposes.
+// It is called by the application at the start of every frame.
+func (r *Renderer) ResetStats() {
+	r.stats.reset()
+}
+
+// BeginQuad is an alias of BeginScene.
+func (r *Renderer) BeginQuad(cameraController *CameraController) {
+	r.BeginScene(cameraController)
+}
+
+// EndQuad is an alias of EndScene.
+func (r *Renderer) EndQuad() {
+	r.EndScene()
 }
 
 // DrawTexturedQuad .
@@ -90,6 +193,98 @@ func (r *Renderer) DrawTexturedQuad(quad *TexturedQuad) {
 	}
 }
 
+// Font returns the font used by DrawText, creating
+// the default font if none was set.
+func (r *Renderer) Font() (*Font, error) {
+	if r.font == nil {
+		font, err := NewDefaultFont()
+		if err != nil {
+			return nil, err
+		}
+		r.font = font
+	}
+	return r.font, nil
+}
+
+// SetFont sets the font used by DrawText.
+func (r *Renderer) SetFont(font *Font) {
+	r.font = font
+}
+
+// DrawText draws str using the renderer font. The origin of transform
+// is located on the baseline of the first line and size is the font size
+// in world units.
+func (r *Renderer) DrawText(str string, transform mgl32.Mat4, size float32, color mgl32.Vec4) {
+	r.DrawTextStyled(&Text{
+		Value:     str,
+		Transform: transform,
+		Size:      size,
+		Color:     color,
+	})
+}
+
+// DrawTextStyled draws text using its alignment and font.
+// Text using an SDF font is rendered with its outline and shadow.
+func (r *Renderer) DrawTextStyled(text *Text) {
+	font := text.Font
+	if font == nil {
+		var err error
+		if font, err = r.Font(); err != nil {
+			panic(err)
+		}
+	}
+	if font.IsSDF() {
+		if err := r.textSDFProgram.addText(font, text); err != nil {
+			panic(err)
+		}
+		return
+	}
+	if err := r.quadProgram.addText(font, text); err != nil {
+		panic(err)
+	}
+}
+
+// DrawLine draws a line of the provided thickness between p0 and p1.
+func (r *Renderer) DrawLine(p0, p1 mgl32.Vec3, thickness float32, color mgl32.Vec4) {
+	r.shapeProgram.AddLine(p0, p1, thickness, color)
+}
+
+// DrawPolyline draws connected lines between consecutive points.
+func (r *Renderer) DrawPolyline(points []mgl32.Vec3, thickness float32, color mgl32.Vec4, closed bool) {
+	r.shapeProgram.AddPolyline(points, thickness, color, closed)
+}
+
+// DrawRect draws a filled unit rectangle transformed by transform.
+func (r *Renderer) DrawRect(transform mgl32.Mat4, color mgl32.Vec4) {
+	r.shapeProgram.AddRect(transform, color)
+}
+
+// DrawRectOutline draws the outline of a unit rectangle transformed by transform.
+func (r *Renderer) DrawRectOutline(transform mgl32.Mat4, thickness float32, color mgl32.Vec4) {
+	r.shapeProgram.AddRectOutline(transform, thickness, color)
+}
+
+// DrawCircle draws a filled circle inscribed in a unit quad transformed by transform.
+func (r *Renderer) DrawCircle(transform mgl32.Mat4, color mgl32.Vec4) {
+	r.shapeProgram.AddCircle(transform, 1, defaultCircleFade, color)
+}
+
+// DrawCircleOutline draws a ring inscribed in a unit quad transformed by transform.
+// Thickness is relative to the radius of the circle, in the range (0, 1].
+func (r *Renderer) DrawCircleOutline(transform mgl32.Mat4, thickness float32, color mgl32.Vec4) {
+	r.shapeProgram.AddCircle(transform, thickness, defaultCircleFade, color)
+}
+
+// DrawTriangle draws a filled triangle.
+func (r *Renderer) DrawTriangle(p0, p1, p2 mgl32.Vec3, color mgl32.Vec4) {
+	r.shapeProgram.AddTriangle(p0, p1, p2, color)
+}
+
+// DrawArrow draws a line from `from` to `to` ending with a triangular head.
+func (r *Renderer) DrawArrow(from, to mgl32.Vec3, thickness, headSize float32, color mgl32.Vec4) {
+	r.shapeProgram.AddArrow(from, to, thickness, headSize, color)
+}
+
 func (r *Renderer) enableDebugging() {
 	gl.Enable(gl.DEBUG_OUTPUT)
 	gl.DebugMessageCallback(func(
@@ -0,0 +1,118 @@
+package renderer
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// sdfPoint is the offset from a pixel to the nearest seed pixel.
+type sdfPoint struct {
+	dx, dy int32
+}
+
+var sdfFar = sdfPoint{dx: 1 << 14, dy: 1 << 14}
+
+func (p sdfPoint) dist2() int64 {
+	return int64(p.dx)*int64(p.dx) + int64(p.dy)*int64(p.dy)
+}
+
+// sdfGrid computes, for each pixel, the offset to
+// the nearest seed pixel using 8SSEDT (8-points signed
+// sequential euclidean distance transform).
+type sdfGrid struct {
+	width  int
+	height int
+	points []sdfPoint
+}
+
+func newSDFGrid(width, height int) *sdfGrid {
+	return &sdfGrid{
+		width:  width,
+		height: height,
+		points: make([]sdfPoint, width*height),
+	}
+}
+
+func (g *sdfGrid) get(x, y int) sdfPoint {
+	if x < 0 || y < 0 || x >= g.width || y >= g.height {
+		return sdfFar
+	}
+	return g.points[y*g.width+x]
+}
+
+func (g *sdfGrid) compare(p *sdfPoint, x, y, offsetX, offsetY int) {
+	other := g.get(x+offsetX, y+offsetY)
+	other.dx += int32(offsetX)
+	other.dy += int32(offsetY)
+	if other.dist2() < p.dist2() {
+		*p = other
+	}
+}
+
+func (g *sdfGrid) generate() {
+	// pass 1: top to bottom
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			p := g.points[y*g.width+x]
+			g.compare(&p, x, y, -1, 0)
+			g.compare(&p, x, y, 0, -1)
+			g.compare(&p, x, y, -1, -1)
+			g.compare(&p, x, y, 1, -1)
+			g.points[y*g.width+x] = p
+		}
+		for x := g.width - 1; x >= 0; x-- {
+			p := g.points[y*g.width+x]
+			g.compare(&p, x, y, 1, 0)
+			g.points[y*g.width+x] = p
+		}
+	}
+	// pass 2: bottom to top
+	for y := g.height - 1; y >= 0; y-- {
+		for x := g.width - 1; x >= 0; x-- {
+			p := g.points[y*g.width+x]
+			g.compare(&p, x, y, 1, 0)
+			g.compare(&p, x, y, 0, 1)
+			g.compare(&p, x, y, -1, 1)
+			g.compare(&p, x, y, 1, 1)
+			g.points[y*g.width+x] = p
+		}
+		for x := 0; x < g.width; x++ {
+			p := g.points[y*g.width+x]
+			g.compare(&p, x, y, -1, 0)
+			g.points[y*g.width+x] = p
+		}
+	}
+}
+
+// signedDistanceField converts a coverage mask into a signed distance field
+// padded by spread pixels on each side. Distances are mapped to [0, 255]
+// with 128 on the glyph edge, higher values inside and lower values outside,
+// saturating at spread pixels from the edge.
+func signedDistanceField(mask image.Image, maskBounds image.Rectangle, maskp image.Point, spread int) *image.Alpha {
+	bounds := image.Rect(0, 0, maskBounds.Dx()+2*spread, maskBounds.Dy()+2*spread)
+	coverage := image.NewAlpha(bounds)
+	draw.Draw(coverage, bounds.Inset(spread), mask, maskp, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	inside := newSDFGrid(width, height)
+	outside := newSDFGrid(width, height)
+	for i, a := range coverage.Pix {
+		if a >= 128 {
+			outside.points[i] = sdfFar
+		} else {
+			inside.points[i] = sdfFar
+		}
+	}
+	inside.generate()
+	outside.generate()
+
+	field := image.NewAlpha(bounds)
+	for i := range field.Pix {
+		// distance to the nearest inside pixel minus distance to the nearest outside pixel
+		dist := math.Sqrt(float64(outside.points[i].dist2())) - math.Sqrt(float64(inside.points[i].dist2()))
+		value := 0.5 + dist/(2*float64(spread))
+		field.Pix[i] = uint8(math.Max(0, math.Min(1, value)) * 255)
+	}
+	return field
+}
@@ -0,0 +1,305 @@
+package renderer
+
+import (
+	"unsafe"
+
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
+	"github.com/devodev/opengl-experiment/internal/opengl"
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	maxShapeVertices = 40000
+	maxShapeIndices  = maxShapeVertices * 3 / 2
+
+	defaultCircleFade = float32(0.005)
+
+	shapeVertexSize = int(unsafe.Sizeof(ShapeVertex{}))
+
+	shapeLayout = opengl.NewVBOLayout(
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 2, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+	)
+)
+
+// Shape batches untextured primitives (lines, rectangles,
+// circles, triangles) into a single draw call.
+type Shape struct {
+	// shape-related rendering primitives
+	vao           *opengl.VAO
+	vbo           *opengl.VBO
+	shaderProgram *opengl.ShaderProgram
+	// shape-related batch rendering data
+	data *shapeData
+
+	stats    *rendererStats
+	profiler *profiler.Profiler
+}
+
+// Init .
+func (s *Shape) Init() error {
+	// initialize shape-related rendering primitives
+	shapeVertexShaderSource := string(append([]byte(shapeVertexShader), byte('\x00')))
+	shapeFragmentShaderSource := string(append([]byte(shapeFragmentShader), byte('\x00')))
+	shaderProgram, err := opengl.NewShaderProgram(shapeVertexShaderSource, shapeFragmentShaderSource)
+	if err != nil {
+		return err
+	}
+
+	// create VBO
+	vbo, err := opengl.NewVBO(maxShapeVertices * shapeVertexSize)
+	if err != nil {
+		return err
+	}
+	vbo.SetLayout(shapeLayout)
+
+	// create IBO
+	ibo := opengl.NewIBO(maxShapeIndices)
+
+	// create VAO and set buffers on it
+	vao := opengl.NewVAO()
+	vao.AddVBO(vbo)
+	vao.SetIBO(ibo)
+
+	s.vao = vao
+	s.vbo = vbo
+	s.shaderProgram = shaderProgram
+	s.data = newShapeData()
+
+	return nil
+}
+
+func (s *Shape) setViewProjectionMatrix(vp mgl32.Mat4) {
+	s.shaderProgram.SetUniformMatrix4fv("vp", 1, false, &vp[0])
+}
+
+// Begin .
+func (s *Shape) Begin(vp mgl32.Mat4) {
+	// reset data each frame
+	s.data.reset()
+	s.setViewProjectionMatrix(vp)
+}
+
+// End .
+func (s *Shape) End() {
+	defer s.profiler.Scope("Shape.End")()
+
+	s.flush()
+}
+
+func (s *Shape) flush() {
+	if len(s.data.Indices) == 0 {
+		return
+	}
+
+	s.vbo.SetData(s.data)
+	s.vao.IBO().SetData(s.data)
+	s.stats.drawCall(0, len(s.data.Vertices), len(s.data.Indices), 0, s.data.VBOSize()+4*int(s.data.IBOCount()))
+
+	s.shaderProgram.Bind()
+	s.vao.Bind()
+
+	// actual draw call
+	count := s.vao.IBO().Count()
+	gl.DrawElements(gl.TRIANGLES, int32(count), gl.UNSIGNED_INT, nil)
+
+	s.vao.Unbind()
+	s.shaderProgram.Unbind()
+
+	s.data.reset()
+}
+
+// reserve flushes the current batch when it cannot
+// hold the requested amount of vertices and indices.
+func (s *Shape) reserve(vertices, indices int) {
+	if len(s.data.Vertices)+vertices > maxShapeVertices || len(s.data.Indices)+indices > maxShapeIndices {
+		s.flush()
+	}
+}
+
+// AddLine adds a line of the provided thickness (in world units) between p0 and p1.
+// The line is extruded in the XY plane.
+func (s *Shape) AddLine(p0, p1 mgl32.Vec3, thickness float32, color mgl32.Vec4) {
+	dir := p1.Sub(p0)
+	normal := mgl32.Vec3{-dir.Y(), dir.X(), 0}
+	if normal.Len() == 0 {
+		normal = mgl32.Vec3{0, 1, 0}
+	}
+	offset := normal.Normalize().Mul(thickness / 2)
+
+	s.reserve(4, 6)
+	s.data.addQuad([4]mgl32.Vec4{
+		p0.Add(offset).Vec4(1),
+		p0.Sub(offset).Vec4(1),
+		p1.Sub(offset).Vec4(1),
+		p1.Add(offset).Vec4(1),
+	}, color, 0, 0)
+}
+
+// AddPolyline adds connected line segments between consecutive points.
+// When closed is true, the last point is connected back to the first one.
+func (s *Shape) AddPolyline(points []mgl32.Vec3, thickness float32, color mgl32.Vec4, closed bool) {
+	for i := 0; i+1 < len(points); i++ {
+		s.AddLine(points[i], points[i+1], thickness, color)
+	}
+	if closed && len(points) > 2 {
+		s.AddLine(points[len(points)-1], points[0], thickness, color)
+	}
+}
+
+// AddRect adds a filled unit rectangle transformed by transform.
+func (s *Shape) AddRect(transform mgl32.Mat4, color mgl32.Vec4) {
+	var positions [4]mgl32.Vec4
+	for i := range positions {
+		positions[i] = transform.Mul4x1(quadVertices[i])
+	}
+	s.reserve(4, 6)
+	s.data.addQuad(positions, color, 0, 0)
+}
+
+// AddRectOutline adds the outline of a unit rectangle transformed by transform.
+func (s *Shape) AddRectOutline(transform mgl32.Mat4, thickness float32, color mgl32.Vec4) {
+	points := make([]mgl32.Vec3, len(quadVertices))
+	for i := range quadVertices {
+		points[i] = transform.Mul4x1(quadVertices[i]).Vec3()
+	}
+	s.AddPolyline(points, thickness, color, true)
+}
+
+// AddCircle adds a circle inscribed in a unit quad transformed by transform.
+// Thickness is relative to the radius: 1 renders a filled circle
+// and values towards 0 render thinner rings.
+func (s *Shape) AddCircle(transform mgl32.Mat4, thickness, fade float32, color mgl32.Vec4) {
+	var positions [4]mgl32.Vec4
+	for i := range positions {
+		positions[i] = transform.Mul4x1(quadVertices[i])
+	}
+	s.reserve(4, 6)
+	s.data.addQuad(positions, color, thickness, fade)
+}
+
+// AddTriangle adds a filled triangle.
+func (s *Shape) AddTriangle(p0, p1, p2 mgl32.Vec3, color mgl32.Vec4) {
+	s.reserve(3, 3)
+	s.data.addTriangle([3]mgl32.Vec4{p0.Vec4(1), p1.Vec4(1), p2.Vec4(1)}, color)
+}
+
+// AddArrow adds a line from `from` to `to` with a triangular head
+// of size headSize at the `to` end.
+func (s *Shape) AddArrow(from, to mgl32.Vec3, thickness, headSize float32, color mgl32.Vec4) {
+	dir := to.Sub(from)
+	length := dir.Len()
+	if length == 0 {
+		return
+	}
+	dir = dir.Mul(1 / length)
+	if headSize > length {
+		headSize = length
+	}
+
+	normal := mgl32.Vec3{-dir.Y(), dir.X(), 0}
+	if normal.Len() == 0 {
+		normal = mgl32.Vec3{0, 1, 0}
+	}
+	normal = normal.Normalize()
+
+	base := to.Sub(dir.Mul(headSize))
+	s.AddLine(from, base, thickness, color)
+	s.AddTriangle(
+		base.Add(normal.Mul(headSize/2)),
+		base.Sub(normal.Mul(headSize/2)),
+		to,
+		color,
+	)
+}
+
+// ShapeVertex .
+type ShapeVertex struct {
+	Position      mgl32.Vec4
+	Color         mgl32.Vec4
+	LocalPosition mgl32.Vec2
+	Thickness     float32
+	Fade          float32
+}
+
+// shapeData .
+type shapeData struct {
+	Vertices []ShapeVertex
+	Indices  []uint32
+}
+
+func newShapeData() *shapeData {
+	return &shapeData{
+		Vertices: make([]ShapeVertex, 0, maxShapeVertices),
+		Indices:  make([]uint32, 0, maxShapeIndices),
+	}
+}
+
+func (d *shapeData) reset() {
+	d.Vertices = d.Vertices[:0]
+	d.Indices = d.Indices[:0]
+}
+
+func (d *shapeData) addQuad(positions [4]mgl32.Vec4, color mgl32.Vec4, thickness, fade float32) {
+	// add indices
+	offset := len(d.Vertices)
+	d.Indices = append(d.Indices,
+		uint32(offset),
+		uint32(offset+1),
+		uint32(offset+2),
+		uint32(offset+2),
+		uint32(offset+3),
+		uint32(offset),
+	)
+
+	// add vertices
+	for i := 0; i < len(positions); i++ {
+		vertex := ShapeVertex{
+			Position:      positions[i],
+			Color:         color,
+			LocalPosition: quadVertices[i].Vec2().Mul(2),
+			Thickness:     thickness,
+			Fade:          fade,
+		}
+		d.Vertices = append(d.Vertices, vertex)
+	}
+}
+
+func (d *shapeData) addTriangle(positions [3]mgl32.Vec4, color mgl32.Vec4) {
+	// add indices
+	offset := len(d.Vertices)
+	d.Indices = append(d.Indices,
+		uint32(offset),
+		uint32(offset+1),
+		uint32(offset+2),
+	)
+
+	// add vertices
+	for i := 0; i < len(positions); i++ {
+		d.Vertices = append(d.Vertices, ShapeVertex{Position: positions[i], Color: color})
+	}
+}
+
+// VBOGLPtr implements the VBOData interface.
+func (d *shapeData) VBOGLPtr() unsafe.Pointer {
+	return gl.Ptr(d.Vertices)
+}
+
+// VBOSize implements the VBOData interface.
+func (d *shapeData) VBOSize() int {
+	return shapeVertexSize * len(d.Vertices)
+}
+
+// IBOGLPtr implements the IBOData interface.
+func (d *shapeData) IBOGLPtr() unsafe.Pointer {
+	return gl.Ptr(d.Indices)
+}
+
+// IBOCount implements the IBOData interface.
+func (d *shapeData) IBOCount() int32 {
+	return int32(len(d.Indices))
+}
@@ -0,0 +1,55 @@
+package renderer
+
+const (
+	shapeVertexShader = `
+#version 460 core
+layout (location = 0) in vec4 position;
+layout (location = 1) in vec4 color;
+layout (location = 2) in vec2 localPosition;
+layout (location = 3) in float thickness;
+layout (location = 4) in float fade;
+
+out vec4 fragColor;
+out vec2 fragLocalPosition;
+out float fragThickness;
+out float fragFade;
+
+uniform mat4 vp;
+
+void main() {
+    fragColor = color;
+    fragLocalPosition = localPosition;
+    fragThickness = thickness;
+    fragFade = fade;
+    gl_Position = vp * position;
+}
+    `
+
+	shapeFragmentShader = `
+#version 460 core
+layout (location = 0) out vec4 outColor;
+
+in vec4 fragColor;
+in vec2 fragLocalPosition;
+in float fragThickness;
+in float fragFade;
+
+void main() {
+    // solid geometry (lines, rectangles, triangles)
+    if (fragThickness <= 0.0) {
+        outColor = fragColor;
+        return;
+    }
+
+    // circles are rendered using a signed distance field
+    // computed from the local position of the fragment
+    float distance = 1.0 - length(fragLocalPosition);
+    float alpha = smoothstep(0.0, fragFade, distance);
+    alpha *= smoothstep(fragThickness + fragFade, fragThickness, distance);
+    if (alpha == 0.0) {
+        discard;
+    }
+    outColor = vec4(fragColor.rgb, fragColor.a * alpha);
+}
+    `
+)
@@ -0,0 +1,62 @@
+package renderer
+
+import "sync"
+
+// Stats are the rendering counters of a frame.
+type Stats struct {
+	DrawCalls int
+	// Quads is the number of quads drawn by the quad batch,
+	// shapes and text being excluded
+	Quads        int
+	Vertices     int
+	Indices      int
+	TextureBinds int
+	ShaderBinds  int
+	// UploadBytes is the size of the vertex and index data uploaded.
+	UploadBytes int
+}
+
+// rendererStats accumulates the counters of the current frame and keeps
+// those of the last completed frame, which can be read from any goroutine.
+type rendererStats struct {
+	current Stats
+
+	mu   sync.Mutex
+	last Stats
+}
+
+// drawCall records a batch draw call.
+func (s *rendererStats) drawCall(quads, vertices, indices, textures, uploadBytes int) {
+	s.current.DrawCalls++
+	s.current.Quads += quads
+	s.current.Vertices += vertices
+	s.current.Indices += indices
+	s.current.TextureBinds += textures
+	s.current.ShaderBinds++
+	s.current.UploadBytes += uploadBytes
+}
+
+// add adds the counters of s to the current frame.
+func (s *rendererStats) add(o Stats) {
+	s.current.DrawCalls += o.DrawCalls
+	s.current.Quads += o.Quads
+	s.current.Vertices += o.Vertices
+	s.current.Indices += o.Indices
+	s.current.TextureBinds += o.TextureBinds
+	s.current.ShaderBinds += o.ShaderBinds
+	s.current.UploadBytes += o.UploadBytes
+}
+
+// reset ends the frame, making its counters available through Stats.
+func (s *rendererStats) reset() {
+	s.mu.Lock()
+	s.last = s.current
+	s.mu.Unlock()
+	s.current = Stats{}
+}
+
+func (s *rendererStats) lastFrame() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
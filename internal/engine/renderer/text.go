@@ -0,0 +1,176 @@
+package renderer
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TextAlign controls the horizontal alignment of each line of a Text.
+type TextAlign int
+
+// TextAlign values
+const (
+	TextAlignLeft TextAlign = iota
+	TextAlignCenter
+	TextAlignRight
+)
+
+// Text is a string drawn using a Font.
+// The origin of Transform is located on the baseline of the first line,
+// at the left, center or right of the line depending on Align.
+// Size is the font size in world units.
+type Text struct {
+	Value     string
+	Transform mgl32.Mat4
+	Size      float32
+	Color     mgl32.Vec4
+	Align     TextAlign
+	// Font defaults to the renderer font when nil.
+	Font *Font
+
+	// Outline and Shadow are only rendered using SDF fonts.
+	Outline *TextOutline
+	Shadow  *TextShadow
+}
+
+// TextOutline draws an outline around glyphs.
+// Width is relative to the distance field spread, in the range (0, 0.5].
+type TextOutline struct {
+	Width float32
+	Color mgl32.Vec4
+}
+
+// TextShadow draws a drop shadow behind glyphs.
+// Offset is relative to the font size and Softness,
+// in the range [0, 0.5], blurs the shadow edge.
+type TextShadow struct {
+	Offset   mgl32.Vec2
+	Softness float32
+	Color    mgl32.Vec4
+}
+
+// textLine is a line of laid out runes. Offsets and width
+// are in pixels of the font used for layout.
+type textLine struct {
+	runes   []rune
+	offsets []float32
+	width   float32
+}
+
+// layoutLines splits str on line breaks and computes the
+// horizontal position of each rune, applying kerning.
+func (f *Font) layoutLines(str string) []textLine {
+	lines := []textLine{{}}
+	current := &lines[0]
+
+	var (
+		pen  float32
+		prev rune = -1
+	)
+	for _, r := range str {
+		if r == '\n' {
+			lines = append(lines, textLine{})
+			current = &lines[len(lines)-1]
+			pen = 0
+			prev = -1
+			continue
+		}
+		if r == '\r' {
+			continue
+		}
+
+		g := f.glyph(r)
+		if g == nil {
+			continue
+		}
+		if prev >= 0 {
+			pen += f.kern(prev, r)
+		}
+		current.runes = append(current.runes, r)
+		current.offsets = append(current.offsets, pen)
+		pen += g.advance
+		current.width = pen
+		prev = r
+	}
+	return lines
+}
+
+// eachGlyph lays out text and calls fn with the transform
+// of each visible glyph quad.
+func (f *Font) eachGlyph(text *Text, fn func(transform mgl32.Mat4, g *fontGlyph) error) error {
+	lines := f.layoutLines(text.Value)
+	f.upload()
+
+	scale := text.Size / f.pixelSize
+	for lineIdx, line := range lines {
+		var alignOffset float32
+		switch text.Align {
+		case TextAlignCenter:
+			alignOffset = -line.width / 2
+		case TextAlignRight:
+			alignOffset = -line.width
+		}
+		baseline := -float32(lineIdx) * f.lineHeight
+
+		for i, r := range line.runes {
+			g := f.glyph(r)
+			if g == nil || !g.visible {
+				continue
+			}
+
+			// glyph bounds have Y pointing down, flip them in world space
+			x0 := alignOffset + line.offsets[i] + float32(g.bounds.Min.X)
+			x1 := alignOffset + line.offsets[i] + float32(g.bounds.Max.X)
+			y0 := baseline - float32(g.bounds.Max.Y)
+			y1 := baseline - float32(g.bounds.Min.Y)
+
+			glyphTransform := text.Transform.
+				Mul4(mgl32.Scale3D(scale, scale, 1)).
+				Mul4(mgl32.Translate3D((x0+x1)/2, (y0+y1)/2, 0)).
+				Mul4(mgl32.Scale3D(x1-x0, y1-y0, 1))
+			if err := fn(glyphTransform, g); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addText lays out text into glyph quads and adds them to the quad batch.
+func (q *Quad) addText(f *Font, text *Text) error {
+	return f.eachGlyph(text, func(transform mgl32.Mat4, g *fontGlyph) error {
+		return q.addTexturedRegion(transform, f.texture, g.texCoords, text.Color)
+	})
+}
+
+// GlyphQuad is a glyph laid out in pixel space, with Y pointing down.
+// TexCoords follow the top-left, bottom-left, bottom-right, top-right order.
+type GlyphQuad struct {
+	Min       mgl32.Vec2
+	Max       mgl32.Vec2
+	TexCoords [4]mgl32.Vec2
+}
+
+// LayoutPixels lays out str at size pixels with the top-left corner of its
+// first line at origin, Y pointing down, and calls fn for each visible glyph.
+// It is meant for screen-space renderers sampling the font texture directly.
+func (f *Font) LayoutPixels(str string, origin mgl32.Vec2, size float32, fn func(GlyphQuad)) {
+	lines := f.layoutLines(str)
+	f.upload()
+
+	scale := size / f.pixelSize
+	for lineIdx, line := range lines {
+		baseline := origin.Y() + (f.ascent+float32(lineIdx)*f.lineHeight)*scale
+		for i, r := range line.runes {
+			g := f.glyph(r)
+			if g == nil || !g.visible {
+				continue
+			}
+			x := origin.X() + line.offsets[i]*scale
+			fn(GlyphQuad{
+				Min:       mgl32.Vec2{x + float32(g.bounds.Min.X)*scale, baseline + float32(g.bounds.Min.Y)*scale},
+				Max:       mgl32.Vec2{x + float32(g.bounds.Max.X)*scale, baseline + float32(g.bounds.Max.Y)*scale},
+				TexCoords: g.texCoords,
+			})
+		}
+	}
+}
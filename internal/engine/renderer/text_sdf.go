@@ -0,0 +1,250 @@
+package renderer
+
+import (
+	"unsafe"
+
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
+	"github.com/devodev/opengl-experiment/internal/opengl"
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	maxTextSDFGlyphs   = 10000
+	maxTextSDFVertices = maxTextSDFGlyphs * 4
+	maxTextSDFIndices  = maxTextSDFGlyphs * 6
+
+	textSDFVertexSize = int(unsafe.Sizeof(TextSDFVertex{}))
+
+	textSDFLayout = opengl.NewVBOLayout(
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 2, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 4, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 2, Normalized: false, DataType: opengl.GLDataTypeFloat},
+		opengl.VBOLayoutElement{Count: 1, Normalized: false, DataType: opengl.GLDataTypeFloat},
+	)
+)
+
+// TextSDF batches glyphs of SDF fonts, rendered using
+// a dedicated shader supporting outlines and drop shadows.
+type TextSDF struct {
+	// sdf text-related rendering primitives
+	vao           *opengl.VAO
+	vbo           *opengl.VBO
+	shaderProgram *opengl.ShaderProgram
+	// sdf text-related batch rendering data
+	data *textSDFData
+
+	stats    *rendererStats
+	profiler *profiler.Profiler
+}
+
+// Init .
+func (t *TextSDF) Init() error {
+	// initialize sdf text-related rendering primitives
+	textSDFVertexShaderSource := string(append([]byte(textSDFVertexShader), byte('\x00')))
+	textSDFFragmentShaderSource := string(append([]byte(textSDFFragmentShader), byte('\x00')))
+	shaderProgram, err := opengl.NewShaderProgram(textSDFVertexShaderSource, textSDFFragmentShaderSource)
+	if err != nil {
+		return err
+	}
+
+	// create VBO
+	vbo, err := opengl.NewVBO(maxTextSDFVertices * textSDFVertexSize)
+	if err != nil {
+		return err
+	}
+	vbo.SetLayout(textSDFLayout)
+
+	// create IBO
+	ibo := opengl.NewIBO(maxTextSDFIndices)
+
+	// create VAO and set buffers on it
+	vao := opengl.NewVAO()
+	vao.AddVBO(vbo)
+	vao.SetIBO(ibo)
+
+	// setup textures
+	samplers := make([]int32, maxTextures)
+	for i := 0; i < maxTextures; i++ {
+		samplers[i] = int32(i)
+	}
+	shaderProgram.SetUniform1iv("tex", int32(len(samplers)), &samplers[0])
+
+	t.vao = vao
+	t.vbo = vbo
+	t.shaderProgram = shaderProgram
+	t.data = newTextSDFData()
+
+	return nil
+}
+
+func (t *TextSDF) setViewProjectionMatrix(vp mgl32.Mat4) {
+	t.shaderProgram.SetUniformMatrix4fv("vp", 1, false, &vp[0])
+}
+
+// Begin .
+func (t *TextSDF) Begin(vp mgl32.Mat4) {
+	// reset data each frame
+	t.data.reset()
+	t.setViewProjectionMatrix(vp)
+}
+
+// End .
+func (t *TextSDF) End() {
+	defer t.profiler.Scope("TextSDF.End")()
+
+	t.flush()
+}
+
+func (t *TextSDF) flush() {
+	if len(t.data.Indices) == 0 {
+		return
+	}
+
+	t.vbo.SetData(t.data)
+	t.vao.IBO().SetData(t.data)
+	t.stats.drawCall(0, len(t.data.Vertices), len(t.data.Indices), len(t.data.Textures), t.data.VBOSize()+4*int(t.data.IBOCount()))
+
+	for _, tex := range t.data.Textures {
+		tex.Bind()
+	}
+	t.shaderProgram.Bind()
+	t.vao.Bind()
+
+	// actual draw call
+	count := t.vao.IBO().Count()
+	gl.DrawElements(gl.TRIANGLES, int32(count), gl.UNSIGNED_INT, nil)
+
+	for _, tex := range t.data.Textures {
+		tex.Unbind()
+	}
+	t.vao.Unbind()
+	t.shaderProgram.Unbind()
+
+	t.data.reset()
+}
+
+// addText lays out text into glyph quads and adds them to the batch.
+func (t *TextSDF) addText(f *Font, text *Text) error {
+	style := textSDFStyle{color: text.Color}
+	if text.Outline != nil {
+		style.outlineColor = text.Outline.Color
+		style.outlineWidth = text.Outline.Width
+	}
+	if text.Shadow != nil {
+		// shadow offset is converted from font size units to atlas
+		// texture coordinates, the atlas V axis points down
+		offset := text.Shadow.Offset.Mul(f.pixelSize / float32(fontAtlasSize))
+		style.shadowColor = text.Shadow.Color
+		style.shadowOffset = mgl32.Vec2{-offset.X(), offset.Y()}
+		style.shadowSoftness = text.Shadow.Softness
+	}
+
+	return f.eachGlyph(text, func(transform mgl32.Mat4, g *fontGlyph) error {
+		if len(t.data.Vertices)+len(quadVertices) > maxTextSDFVertices {
+			t.flush()
+		}
+		t.data.addGlyph(transform, f.texture, g.texCoords, style)
+		return nil
+	})
+}
+
+// TextSDFVertex .
+type TextSDFVertex struct {
+	Position       mgl32.Vec4
+	TexCoord       mgl32.Vec2
+	TexIndex       float32
+	Color          mgl32.Vec4
+	OutlineColor   mgl32.Vec4
+	OutlineWidth   float32
+	ShadowColor    mgl32.Vec4
+	ShadowOffset   mgl32.Vec2
+	ShadowSoftness float32
+}
+
+// textSDFStyle holds the per-vertex style attributes of a text.
+type textSDFStyle struct {
+	color          mgl32.Vec4
+	outlineColor   mgl32.Vec4
+	outlineWidth   float32
+	shadowColor    mgl32.Vec4
+	shadowOffset   mgl32.Vec2
+	shadowSoftness float32
+}
+
+// textSDFData .
+type textSDFData struct {
+	Textures map[int]opengl.Texture
+	Vertices []TextSDFVertex
+	Indices  []uint32
+}
+
+func newTextSDFData() *textSDFData {
+	return &textSDFData{
+		Textures: make(map[int]opengl.Texture),
+		Vertices: make([]TextSDFVertex, 0, maxTextSDFVertices),
+		Indices:  make([]uint32, 0, maxTextSDFIndices),
+	}
+}
+
+func (d *textSDFData) reset() {
+	d.Textures = make(map[int]opengl.Texture)
+	d.Vertices = d.Vertices[:0]
+	d.Indices = d.Indices[:0]
+}
+
+func (d *textSDFData) addGlyph(transform mgl32.Mat4, texture opengl.Texture, texCoords [4]mgl32.Vec2, style textSDFStyle) {
+	d.Textures[texture.Index()] = texture
+
+	// add indices
+	offset := len(d.Vertices)
+	d.Indices = append(d.Indices,
+		uint32(offset),
+		uint32(offset+1),
+		uint32(offset+2),
+		uint32(offset+2),
+		uint32(offset+3),
+		uint32(offset),
+	)
+
+	// add vertices
+	for i := 0; i < len(quadVertices); i++ {
+		vertex := TextSDFVertex{
+			Position:       transform.Mul4x1(quadVertices[i]),
+			TexCoord:       texCoords[i],
+			TexIndex:       float32(texture.Index()),
+			Color:          style.color,
+			OutlineColor:   style.outlineColor,
+			OutlineWidth:   style.outlineWidth,
+			ShadowColor:    style.shadowColor,
+			ShadowOffset:   style.shadowOffset,
+			ShadowSoftness: style.shadowSoftness,
+		}
+		d.Vertices = append(d.Vertices, vertex)
+	}
+}
+
+// VBOGLPtr implements the VBOData interface.
+func (d *textSDFData) VBOGLPtr() unsafe.Pointer {
+	return gl.Ptr(d.Vertices)
+}
+
+// VBOSize implements the VBOData interface.
+func (d *textSDFData) VBOSize() int {
+	return textSDFVertexSize * len(d.Vertices)
+}
+
+// IBOGLPtr implements the IBOData interface.
+func (d *textSDFData) IBOGLPtr() unsafe.Pointer {
+	return gl.Ptr(d.Indices)
+}
+
+// IBOCount implements the IBOData interface.
+func (d *textSDFData) IBOCount() int32 {
+	return int32(len(d.Indices))
+}
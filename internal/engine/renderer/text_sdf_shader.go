@@ -0,0 +1,90 @@
+package renderer
+
+const (
+	textSDFVertexShader = `
+#version 460 core
+layout (location = 0) in vec4 position;
+layout (location = 1) in vec2 texCoord;
+layout (location = 2) in float texIndex;
+layout (location = 3) in vec4 color;
+layout (location = 4) in vec4 outlineColor;
+layout (location = 5) in float outlineWidth;
+layout (location = 6) in vec4 shadowColor;
+layout (location = 7) in vec2 shadowOffset;
+layout (location = 8) in float shadowSoftness;
+
+out vec2 fragTexCoord;
+flat out float fragTexIndex;
+out vec4 fragColor;
+out vec4 fragOutlineColor;
+out float fragOutlineWidth;
+out vec4 fragShadowColor;
+out vec2 fragShadowOffset;
+out float fragShadowSoftness;
+
+uniform mat4 vp;
+
+void main() {
+    fragTexCoord = texCoord;
+    fragTexIndex = texIndex;
+    fragColor = color;
+    fragOutlineColor = outlineColor;
+    fragOutlineWidth = outlineWidth;
+    fragShadowColor = shadowColor;
+    fragShadowOffset = shadowOffset;
+    fragShadowSoftness = shadowSoftness;
+    gl_Position = vp * position;
+}
+    `
+
+	textSDFFragmentShader = `
+#version 460 core
+layout (location = 0) out vec4 outColor;
+
+in vec2 fragTexCoord;
+flat in float fragTexIndex;
+in vec4 fragColor;
+in vec4 fragOutlineColor;
+in float fragOutlineWidth;
+in vec4 fragShadowColor;
+in vec2 fragShadowOffset;
+in float fragShadowSoftness;
+
+uniform sampler2D tex[32];
+
+void main() {
+    int index = int(fragTexIndex);
+    float dist = texture(tex[index], fragTexCoord).a;
+
+    // anti-aliasing width computed in screen space keeps
+    // edges crisp at any zoom level
+    float aa = max(fwidth(dist) * 0.75, 0.001);
+
+    float fill = smoothstep(0.5 - aa, 0.5 + aa, dist);
+    vec4 color = vec4(fragColor.rgb, fragColor.a * fill);
+
+    if (fragOutlineWidth > 0.0) {
+        float edge = 0.5 - fragOutlineWidth;
+        float outline = smoothstep(edge - aa, edge + aa, dist);
+        color = mix(vec4(fragOutlineColor.rgb, fragOutlineColor.a * outline), fragColor, fill);
+    }
+
+    if (fragShadowColor.a > 0.0) {
+        float edge = 0.5 - fragOutlineWidth;
+        float shadowDist = texture(tex[index], fragTexCoord + fragShadowOffset).a;
+        float shadow = smoothstep(edge - fragShadowSoftness - aa, edge + fragShadowSoftness + aa, shadowDist);
+        float shadowAlpha = fragShadowColor.a * shadow * (1.0 - color.a);
+
+        // composite glyph over shadow
+        float alpha = color.a + shadowAlpha;
+        vec3 rgb = (color.rgb * color.a + fragShadowColor.rgb * shadowAlpha) / max(alpha, 0.0001);
+        color = vec4(rgb, alpha);
+    }
+
+    if (color.a <= 0.0) {
+        discard;
+    }
+    outColor = color;
+}
+    `
+)
@@ -0,0 +1,69 @@
+package replay
+
+import (
+	"fmt"
+
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// eventJSON is the JSON representation of the input events, the
+// fields used depending on the event type.
+type eventJSON struct {
+	Type     string             `json:"type"`
+	Key      window.Key         `json:"key,omitempty"`
+	Scancode int                `json:"scancode,omitempty"`
+	Repeat   bool               `json:"repeat,omitempty"`
+	Char     rune               `json:"char,omitempty"`
+	Button   window.MouseButton `json:"button,omitempty"`
+	Mods     window.ModifierKey `json:"mods,omitempty"`
+	X        float64            `json:"x,omitempty"`
+	Y        float64            `json:"y,omitempty"`
+	Focused  bool               `json:"focused,omitempty"`
+}
+
+func encodeEvent(e window.Event) (eventJSON, error) {
+	j := eventJSON{Type: e.Type().String()}
+	switch e := e.(type) {
+	case *window.KeyPressedEvent:
+		j.Key, j.Scancode, j.Mods, j.Repeat = e.Key, e.Scancode, e.Mods, e.Repeat
+	case *window.KeyReleasedEvent:
+		j.Key, j.Scancode, j.Mods = e.Key, e.Scancode, e.Mods
+	case *window.CharTypedEvent:
+		j.Char = e.Char
+	case *window.MouseButtonPressedEvent:
+		j.Button, j.Mods = e.Button, e.Mods
+	case *window.MouseButtonReleasedEvent:
+		j.Button, j.Mods = e.Button, e.Mods
+	case *window.MouseMovedEvent:
+		j.X, j.Y = e.X, e.Y
+	case *window.MouseScrolledEvent:
+		j.X, j.Y = e.XOffset, e.YOffset
+	case *window.FocusChangedEvent:
+		j.Focused = e.Focused
+	default:
+		return eventJSON{}, fmt.Errorf("cannot record %s event", e.Type())
+	}
+	return j, nil
+}
+
+func decodeEvent(j eventJSON) (window.Event, error) {
+	switch j.Type {
+	case window.EventTypeKeyPressed.String():
+		return &window.KeyPressedEvent{Key: j.Key, Scancode: j.Scancode, Mods: j.Mods, Repeat: j.Repeat}, nil
+	case window.EventTypeKeyReleased.String():
+		return &window.KeyReleasedEvent{Key: j.Key, Scancode: j.Scancode, Mods: j.Mods}, nil
+	case window.EventTypeCharTyped.String():
+		return &window.CharTypedEvent{Char: j.Char}, nil
+	case window.EventTypeMouseButtonPressed.String():
+		return &window.MouseButtonPressedEvent{Button: j.Button, Mods: j.Mods}, nil
+	case window.EventTypeMouseButtonReleased.String():
+		return &window.MouseButtonReleasedEvent{Button: j.Button, Mods: j.Mods}, nil
+	case window.EventTypeMouseMoved.String():
+		return &window.MouseMovedEvent{X: j.X, Y: j.Y}, nil
+	case window.EventTypeMouseScrolled.String():
+		return &window.MouseScrolledEvent{XOffset: j.X, YOffset: j.Y}, nil
+	case window.EventTypeFocusChanged.String():
+		return &window.FocusChangedEvent{Focused: j.Focused}, nil
+	}
+	return nil, fmt.Errorf("unknown event type %q", j.Type)
+}
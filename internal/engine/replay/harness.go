@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"fmt"
+
+	"github.com/devodev/opengl-experiment/internal/engine/input"
+	"github.com/devodev/opengl-experiment/internal/engine/simulation"
+	"github.com/devodev/opengl-experiment/internal/engine/timestep"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Harness replays a recording headlessly, without a platform window nor an
+// OpenGL context, so that the simulation of a recorded session can be run
+// from tests or tools. Every step replays a frame through a window which is
+// never initialized, using the same simulation step as the application:
+// the action map is updated from its input snapshot, then the fixed steps
+// and the update run with the recorded delta time:
+//
+//	rec, err := replay.Load("session.jsonl")
+//	h, err := replay.NewHarness(rec)
+//	h.Input().AddContext(gameplayContext)
+//	h.Input().PushContext("gameplay")
+//	h.Run(world.FixedUpdate, world.Update)
+//
+// Events are passed to the event callback of the window, if any.
+type Harness struct {
+	player    *Player
+	window    *window.Window
+	input     *input.ActionMap
+	fixedStep *timestep.FixedStep
+	sim       *simulation.Simulation
+}
+
+// NewHarness creates a harness replaying rec, using a window with the
+// recorded size and options, and a fixed step with the recorded tick
+// rate when the fixed timestep mode was enabled.
+func NewHarness(rec *Recording, options ...window.Option) (*Harness, error) {
+	options = append([]window.Option{
+		window.WithDimensionsOption(rec.Header.Width, rec.Header.Height),
+	}, options...)
+	w, err := window.New(options...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating window: %s", err)
+	}
+	h := &Harness{
+		player: NewPlayer(rec),
+		window: w,
+		input:  input.NewActionMap(),
+	}
+	if rec.Header.TickRate > 0 {
+		f, err := timestep.NewFixedStep(rec.Header.TickRate, rec.Header.MaxSteps)
+		if err != nil {
+			return nil, fmt.Errorf("error creating fixed step: %s", err)
+		}
+		h.fixedStep = f
+	}
+	h.sim = simulation.New(w, h.input, h.fixedStep)
+	return h, nil
+}
+
+// Step replays the next frame, calling the hooks as the application does
+// with its layers. It returns the delta time and the number of fixed steps
+// run, always 0 without a fixed step, or false once every frame was
+// replayed.
+func (h *Harness) Step(hooks simulation.Hooks) (float64, int, bool) {
+	f, ok := h.player.Next()
+	if !ok {
+		return 0, 0, false
+	}
+	steps := h.sim.Step(f.Delta, &f.Input, hooks)
+	return f.Delta, steps, true
+}
+
+// Run replays the remaining frames. For every frame, fixedUpdate is called
+// once per fixed step with the step duration, then update with the delta
+// time. Either function may be nil. It returns the number of frames
+// replayed.
+func (h *Harness) Run(fixedUpdate, update func(dt float64)) int {
+	hooks := simulation.Hooks{FixedUpdate: fixedUpdate, Update: update}
+	n := 0
+	for {
+		if _, _, ok := h.Step(hooks); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// FixedStep returns the fixed step advanced every step, or nil when the
+// recording was made without the fixed timestep mode. Its Alpha is the
+// interpolation alpha the application passes to rendering.
+func (h *Harness) FixedStep() *timestep.FixedStep {
+	return h.fixedStep
+}
+
+// Window returns the window the frames are replayed through.
+func (h *Harness) Window() *window.Window {
+	return h.window
+}
+
+// Input returns the action map updated every step. It has no context, they
+// must be added and pushed as done by the application and its layers.
+func (h *Harness) Input() *input.ActionMap {
+	return h.input
+}
+
+// Frame returns the number of frames replayed.
+func (h *Harness) Frame() int {
+	return h.player.Frame()
+}
@@ -0,0 +1,280 @@
+// Package replay records the input received by the window during a session,
+// with the delta time of every frame, and plays it back to reproduce the
+// same simulation, either through the application or headlessly.
+//
+// A recording is a JSON lines file: a header line, followed by one line
+// per frame holding its delta time and input:
+//
+//	{"format":"input-recording","version":1,"width":1024,"height":768,"tick_rate":60,"max_steps":5}
+//	{"dt":0.0166,"focused":true,"warp":{"x":512,"y":384}}
+//	{"dt":0.0167,"focused":true,"events":[{"type":"KeyPressed","key":87,"scancode":25}]}
+//
+// Replaying is deterministic as long as the simulation only depends on the
+// input and delta times, which excludes wall clock time and unseeded random.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Version is the version of the format written.
+const Version = 1
+
+const format = "input-recording"
+
+// Errors
+var (
+	ErrInvalidRecording   = errors.New("invalid input recording")
+	ErrUnsupportedVersion = errors.New("unsupported input recording version")
+)
+
+// Header describes the session a recording was made in.
+type Header struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	// Width and Height are the window size when recording started
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// TickRate is the fixed timestep rate, 0 when disabled,
+	// and MaxSteps the largest number of fixed steps per frame
+	TickRate float64 `json:"tick_rate,omitempty"`
+	MaxSteps int     `json:"max_steps,omitempty"`
+}
+
+// Frame is the input of a frame and its delta time, in seconds.
+type Frame struct {
+	Delta float64
+	Input window.InputFrame
+}
+
+// frameJSON is the JSON representation of a Frame.
+type frameJSON struct {
+	Delta    float64       `json:"dt"`
+	Focused  bool          `json:"focused,omitempty"`
+	Warp     *cursorJSON   `json:"warp,omitempty"`
+	Events   []eventJSON   `json:"events,omitempty"`
+	Gamepads []gamepadJSON `json:"gamepads,omitempty"`
+}
+
+type cursorJSON struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type gamepadJSON struct {
+	Joystick window.Joystick                     `json:"joystick"`
+	Name     string                              `json:"name"`
+	Buttons  [window.GamepadButtonLast + 1]bool  `json:"buttons"`
+	Axes     [window.GamepadAxisLast + 1]float32 `json:"axes"`
+}
+
+func encodeFrame(f *Frame) (*frameJSON, error) {
+	j := &frameJSON{
+		Delta:   f.Delta,
+		Focused: f.Input.Focused,
+		Warp:    (*cursorJSON)(f.Input.Warp),
+	}
+	for _, e := range f.Input.Events {
+		ej, err := encodeEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		j.Events = append(j.Events, ej)
+	}
+	for _, g := range f.Input.Gamepads {
+		j.Gamepads = append(j.Gamepads, gamepadJSON(g))
+	}
+	return j, nil
+}
+
+func decodeFrame(j *frameJSON) (*Frame, error) {
+	f := &Frame{
+		Delta: j.Delta,
+		Input: window.InputFrame{
+			Focused: j.Focused,
+			Warp:    (*window.CursorPos)(j.Warp),
+		},
+	}
+	for _, ej := range j.Events {
+		e, err := decodeEvent(ej)
+		if err != nil {
+			return nil, err
+		}
+		f.Input.Events = append(f.Input.Events, e)
+	}
+	for _, g := range j.Gamepads {
+		f.Input.Gamepads = append(f.Input.Gamepads, window.GamepadFrame(g))
+	}
+	return f, nil
+}
+
+// Writer writes a recording, one frame at a time.
+type Writer struct {
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	closer io.Closer
+	frames int
+}
+
+// NewWriter writes the header of a recording to w. The format and
+// version of the header are set by the writer.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	buf := bufio.NewWriter(w)
+	rw := &Writer{buf: buf, enc: json.NewEncoder(buf)}
+	header.Format = format
+	header.Version = Version
+	if err := rw.enc.Encode(header); err != nil {
+		return nil, fmt.Errorf("error writing input recording header: %s", err)
+	}
+	return rw, nil
+}
+
+// Create creates the recording file at path.
+func Create(path string, header Header) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f, header)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.closer = f
+	return w, nil
+}
+
+// WriteFrame .
+func (w *Writer) WriteFrame(f *Frame) error {
+	j, err := encodeFrame(f)
+	if err != nil {
+		return err
+	}
+	if err := w.enc.Encode(j); err != nil {
+		return fmt.Errorf("error writing input recording frame: %s", err)
+	}
+	w.frames++
+	return nil
+}
+
+// Frames returns the number of frames written.
+func (w *Writer) Frames() int {
+	return w.frames
+}
+
+// Flush writes the buffered frames.
+func (w *Writer) Flush() error {
+	return w.buf.Flush()
+}
+
+// Close flushes the buffered frames, and closes the file opened by Create.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+		w.closer = nil
+	}
+	return err
+}
+
+// Recording is a recording read in memory.
+type Recording struct {
+	Header Header
+	Frames []*Frame
+}
+
+// Read reads a recording from r.
+func Read(r io.Reader) (*Recording, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.DisallowUnknownFields()
+
+	rec := &Recording{}
+	if err := dec.Decode(&rec.Header); err != nil {
+		return nil, fmt.Errorf("%w: error reading header: %s", ErrInvalidRecording, err)
+	}
+	if rec.Header.Format != format {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidRecording, rec.Header.Format)
+	}
+	if rec.Header.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, rec.Header.Version)
+	}
+
+	for {
+		var j frameJSON
+		if err := dec.Decode(&j); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: error reading frame %d: %s", ErrInvalidRecording, len(rec.Frames), err)
+		}
+		f, err := decodeFrame(&j)
+		if err != nil {
+			return nil, fmt.Errorf("%w: frame %d: %s", ErrInvalidRecording, len(rec.Frames), err)
+		}
+		rec.Frames = append(rec.Frames, f)
+	}
+	return rec, nil
+}
+
+// Load reads the recording file at path.
+func Load(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Duration returns the sum of the delta times of the frames, in seconds.
+func (r *Recording) Duration() float64 {
+	var d float64
+	for _, f := range r.Frames {
+		d += f.Delta
+	}
+	return d
+}
+
+// Player steps through the frames of a recording.
+type Player struct {
+	rec   *Recording
+	frame int
+}
+
+// NewPlayer .
+func NewPlayer(rec *Recording) *Player {
+	return &Player{rec: rec}
+}
+
+// Next returns the next frame, or false once every frame was played.
+func (p *Player) Next() (*Frame, bool) {
+	if p.Done() {
+		return nil, false
+	}
+	f := p.rec.Frames[p.frame]
+	p.frame++
+	return f, true
+}
+
+// Done reports whether every frame was played.
+func (p *Player) Done() bool {
+	return p.frame >= len(p.rec.Frames)
+}
+
+// Frame returns the number of frames played.
+func (p *Player) Frame() int {
+	return p.frame
+}
+
+// Recording .
+func (p *Player) Recording() *Recording {
+	return p.rec
+}
@@ -0,0 +1,206 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devodev/opengl-experiment/internal/engine/input"
+	"github.com/devodev/opengl-experiment/internal/engine/simulation"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+func writeRecording(t *testing.T, header Header, frames []*Frame) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, header)
+	if err != nil {
+		t.Fatalf("NewWriter: %s", err)
+	}
+	for i, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatalf("WriteFrame %d: %s", i, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	if w.Frames() != len(frames) {
+		t.Fatalf("Frames() = %d, want %d", w.Frames(), len(frames))
+	}
+	return &buf
+}
+
+func TestRoundTrip(t *testing.T) {
+	header := Header{Width: 800, Height: 600, TickRate: 60, MaxSteps: 5}
+	frames := []*Frame{
+		{
+			Delta: 1.0 / 60,
+			Input: window.InputFrame{
+				Warp:    &window.CursorPos{X: 0, Y: 0},
+				Focused: true,
+			},
+		},
+		{
+			Delta: 0.0171234,
+			Input: window.InputFrame{
+				Focused: true,
+				Events: []window.Event{
+					&window.KeyPressedEvent{Key: window.KeyW, Scancode: 25},
+					&window.KeyPressedEvent{Key: window.KeyUnknown, Scancode: 135, Mods: window.ModShift, Repeat: true},
+					&window.KeyReleasedEvent{Key: window.KeyUnknown, Scancode: 135, Mods: window.ModCapsLock},
+					&window.CharTypedEvent{Char: 'é'},
+					&window.MouseButtonPressedEvent{Button: window.MouseButton1, Mods: window.ModControl},
+					&window.MouseButtonReleasedEvent{Button: window.MouseButton1},
+					&window.MouseMovedEvent{X: 12.5, Y: -3.25},
+					&window.MouseScrolledEvent{XOffset: 0, YOffset: -1},
+					&window.FocusChangedEvent{Focused: false},
+					&window.FocusChangedEvent{Focused: true},
+				},
+				Gamepads: []window.GamepadFrame{
+					{
+						Joystick: window.Joystick1,
+						Name:     "Xbox Controller",
+						Buttons:  [window.GamepadButtonLast + 1]bool{window.GamepadButtonA: true},
+						Axes:     [window.GamepadAxisLast + 1]float32{0.1, -0.33333334, 1, -1, 0, 0.7071068},
+					},
+				},
+			},
+		},
+		{
+			Delta: 0.25,
+			Input: window.InputFrame{
+				Warp: &window.CursorPos{X: 512, Y: 384.5},
+			},
+		},
+	}
+
+	buf := writeRecording(t, header, frames)
+	rec, err := Read(buf)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+
+	header.Format, header.Version = format, Version
+	if rec.Header != header {
+		t.Errorf("header = %+v, want %+v", rec.Header, header)
+	}
+	if len(rec.Frames) != len(frames) {
+		t.Fatalf("read %d frames, want %d", len(rec.Frames), len(frames))
+	}
+	for i := range frames {
+		if !reflect.DeepEqual(rec.Frames[i], frames[i]) {
+			t.Errorf("frame %d = %+v, want %+v", i, rec.Frames[i], frames[i])
+		}
+	}
+	if rec.Frames[1].Input.Warp != nil {
+		t.Errorf("frame 1 warp = %+v, want nil", rec.Frames[1].Input.Warp)
+	}
+	if rec.Frames[0].Input.Warp == nil {
+		t.Errorf("frame 0 warp is nil, want the zero position")
+	}
+}
+
+func TestReadRejectsHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		err    error
+	}{
+		{"format", `{"format":"asset-pack","version":1,"width":1,"height":1}`, ErrInvalidRecording},
+		{"missing format", `{"version":1,"width":1,"height":1}`, ErrInvalidRecording},
+		{"version", `{"format":"input-recording","version":2,"width":1,"height":1}`, ErrUnsupportedVersion},
+		{"no version", `{"format":"input-recording","width":1,"height":1}`, ErrUnsupportedVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.header + "\n"))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Read() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestHarness(t *testing.T) {
+	const dt = 1.0 / 30
+	press := &window.KeyPressedEvent{Key: window.KeySpace, Scancode: 65}
+	release := &window.KeyReleasedEvent{Key: window.KeySpace, Scancode: 65}
+	events := [][]window.Event{
+		nil,
+		{press},
+		nil,
+		{release},
+		nil,
+		// pressed and released within the same frame
+		{press, release},
+		nil,
+	}
+	var frames []*Frame
+	for _, e := range events {
+		frames = append(frames, &Frame{Delta: dt, Input: window.InputFrame{Focused: true, Events: e}})
+	}
+	buf := writeRecording(t, Header{Width: 640, Height: 480, TickRate: 60}, frames)
+	rec, err := Read(buf)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+
+	h, err := NewHarness(rec)
+	if err != nil {
+		t.Fatalf("NewHarness: %s", err)
+	}
+	if w, hh := h.Window().GetSize(); w != 640 || hh != 480 {
+		t.Errorf("window size = %dx%d, want 640x480", w, hh)
+	}
+	h.Input().AddContext(input.NewContext("test").Bind("jump", input.Key(window.KeySpace, 0)))
+	if err := h.Input().PushContext("test"); err != nil {
+		t.Fatalf("PushContext: %s", err)
+	}
+
+	type edges struct{ down, pressed, up bool }
+	want := []edges{
+		{false, false, false},
+		{true, true, false},
+		{false, true, false},
+		{false, false, true},
+		{false, false, false},
+		{true, false, true},
+		{false, false, false},
+	}
+	var got []edges
+	var fixedSteps int
+	n := h.Run(
+		func(step float64) {
+			if step != 1.0/60 {
+				t.Errorf("fixed step = %g, want %g", step, 1.0/60)
+			}
+			fixedSteps++
+		},
+		func(delta float64) {
+			if delta != dt {
+				t.Errorf("frame %d delta = %g, want %g", len(got), delta, dt)
+			}
+			m := h.Input()
+			got = append(got, edges{m.Down("jump"), m.Pressed("jump"), m.Up("jump")})
+		},
+	)
+
+	if n != len(frames) || h.Frame() != len(frames) {
+		t.Errorf("replayed %d frames (Frame() = %d), want %d", n, h.Frame(), len(frames))
+	}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Errorf("frame %d: got %+v, want %+v", i, got, want)
+			break
+		}
+	}
+	if want := 2 * len(frames); fixedSteps != want {
+		t.Errorf("ran %d fixed steps, want %d", fixedSteps, want)
+	}
+	if _, _, ok := h.Step(simulation.Hooks{}); ok {
+		t.Errorf("Step() after the last frame returned true")
+	}
+}
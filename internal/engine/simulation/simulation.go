@@ -0,0 +1,88 @@
+// Package simulation runs the update of a frame: polling or replaying the
+// input, updating the actions, then running the fixed steps and the
+// variable update. It is shared by the application and the headless replay
+// harness, so that a replayed session is simulated in the same order.
+package simulation
+
+import (
+	"github.com/devodev/opengl-experiment/internal/engine/input"
+	"github.com/devodev/opengl-experiment/internal/engine/profiler"
+	"github.com/devodev/opengl-experiment/internal/engine/timestep"
+	"github.com/devodev/opengl-experiment/internal/engine/window"
+)
+
+// Hooks are the functions called while stepping a frame. Any may be nil.
+type Hooks struct {
+	// Source returns the source the actions are computed from,
+	// given the input of the window.
+	Source func(src input.Source) input.Source
+	// Input is called once the actions are updated, before the updates.
+	Input func(dt float64)
+	// FixedUpdate is called once per fixed step due this frame.
+	FixedUpdate func(dt float64)
+	// Update is called last with the frame delta time.
+	Update func(dt float64)
+}
+
+// Simulation steps the frames of a window.
+type Simulation struct {
+	window    *window.Window
+	input     *input.ActionMap
+	fixedStep *timestep.FixedStep
+	profiler  *profiler.Profiler
+}
+
+// New creates a simulation updating actions from the input of w. The fixed
+// step may be nil, in which case no fixed step is ever run.
+func New(w *window.Window, actions *input.ActionMap, fixedStep *timestep.FixedStep) *Simulation {
+	return &Simulation{
+		window:    w,
+		input:     actions,
+		fixedStep: fixedStep,
+	}
+}
+
+// SetProfiler sets the profiler recording the scopes of a frame.
+func (s *Simulation) SetProfiler(p *profiler.Profiler) {
+	s.profiler = p
+}
+
+// Step runs a frame of dt seconds. The input is replayed from replayed
+// when not nil, polled from the window otherwise. It returns the number
+// of fixed steps run.
+func (s *Simulation) Step(dt float64, replayed *window.InputFrame, hooks Hooks) int {
+	endScope := s.profiler.Scope("Simulation.PollEvents")
+	if replayed != nil {
+		s.window.ReplayEvents(replayed)
+	} else {
+		s.window.PollEvents()
+	}
+	var src input.Source = s.window.Input()
+	if hooks.Source != nil {
+		src = hooks.Source(src)
+	}
+	s.input.Update(src)
+	endScope()
+
+	if hooks.Input != nil {
+		hooks.Input(dt)
+	}
+
+	// run fixed steps before the variable update
+	steps := 0
+	if s.fixedStep != nil {
+		endScope := s.profiler.Scope("Simulation.FixedUpdate")
+		steps = s.fixedStep.Advance(dt)
+		for i := 0; hooks.FixedUpdate != nil && i < steps; i++ {
+			hooks.FixedUpdate(s.fixedStep.Step())
+		}
+		endScope()
+	}
+
+	endScope = s.profiler.Scope("Simulation.Update")
+	if hooks.Update != nil {
+		hooks.Update(dt)
+	}
+	endScope()
+	return steps
+}
@@ -0,0 +1,105 @@
+// Package timestep splits variable frame times into fixed simulation
+// steps, so that a simulation advances the same way whatever the frame rate.
+package timestep
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	defaultFixedStepMaxSteps = 5
+	// defaultFixedStepMaxFrameTime is the largest frame delta accounted
+	// for, so that a long stall (breakpoint, window drag) does not
+	// trigger a burst of catch-up steps.
+	defaultFixedStepMaxFrameTime = 0.25
+)
+
+// FixedStep accumulates frame time and splits it into steps of a fixed
+// duration. The time left in the accumulator is exposed as an
+// interpolation alpha used to blend the last two simulated states.
+type FixedStep struct {
+	step         float64
+	maxSteps     int
+	maxFrameTime float64
+
+	accumulator float64
+	alpha       float64
+	// number of steps run and time dropped during the last frame
+	steps   int
+	dropped float64
+}
+
+// NewFixedStep creates a fixed step running at tickRate steps per second
+// and at most maxSteps steps per frame. A maxSteps of 0 uses the default.
+func NewFixedStep(tickRate float64, maxSteps int) (*FixedStep, error) {
+	if maxSteps <= 0 {
+		maxSteps = defaultFixedStepMaxSteps
+	}
+	f := &FixedStep{
+		maxSteps:     maxSteps,
+		maxFrameTime: defaultFixedStepMaxFrameTime,
+	}
+	if err := f.SetTickRate(tickRate); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetTickRate sets the number of steps per second.
+func (f *FixedStep) SetTickRate(tickRate float64) error {
+	if tickRate <= 0 {
+		return errors.New("tick rate must be positive")
+	}
+	f.step = 1 / tickRate
+	return nil
+}
+
+// MaxSteps returns the largest number of steps run per frame.
+func (f *FixedStep) MaxSteps() int {
+	return f.maxSteps
+}
+
+// TickRate returns the number of steps per second.
+func (f *FixedStep) TickRate() float64 {
+	return 1 / f.step
+}
+
+// Step returns the duration of a step, in seconds.
+func (f *FixedStep) Step() float64 {
+	return f.step
+}
+
+// Advance adds deltaTime to the accumulator and returns the number
+// of steps to run this frame. When more than maxSteps are due, the
+// extra time is dropped to avoid falling further behind every frame.
+func (f *FixedStep) Advance(deltaTime float64) int {
+	f.accumulator += math.Min(deltaTime, f.maxFrameTime)
+
+	steps := int(f.accumulator / f.step)
+	f.dropped = 0
+	if steps > f.maxSteps {
+		f.dropped = float64(steps-f.maxSteps) * f.step
+		steps = f.maxSteps
+	}
+	f.accumulator -= float64(steps)*f.step + f.dropped
+	f.alpha = f.accumulator / f.step
+	f.steps = steps
+	return steps
+}
+
+// Alpha returns the fraction of a step left in the accumulator,
+// in the range [0, 1).
+func (f *FixedStep) Alpha() float64 {
+	return f.alpha
+}
+
+// Steps returns the number of steps run during the last frame.
+func (f *FixedStep) Steps() int {
+	return f.steps
+}
+
+// Dropped returns the simulation time dropped during the last frame, in seconds.
+func (f *FixedStep) Dropped() float64 {
+	return f.dropped
+}
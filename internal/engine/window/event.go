@@ -0,0 +1,171 @@
+package window
+
+import "fmt"
+
+// EventType identifies the type of an Event.
+type EventType int
+
+// Event types
+const (
+	EventTypeKeyPressed EventType = iota
+	EventTypeKeyReleased
+	EventTypeCharTyped
+	EventTypeMouseButtonPressed
+	EventTypeMouseButtonReleased
+	EventTypeMouseMoved
+	EventTypeMouseScrolled
+	EventTypeWindowResized
+	EventTypeWindowClosed
+	EventTypeFocusChanged
+	EventTypeFileDropped
+	EventTypeGamepadConnected
+	EventTypeGamepadDisconnected
+)
+
+var eventTypeNames = map[EventType]string{
+	EventTypeKeyPressed:          "KeyPressed",
+	EventTypeKeyReleased:         "KeyReleased",
+	EventTypeCharTyped:           "CharTyped",
+	EventTypeMouseButtonPressed:  "MouseButtonPressed",
+	EventTypeMouseButtonReleased: "MouseButtonReleased",
+	EventTypeMouseMoved:          "MouseMoved",
+	EventTypeMouseScrolled:       "MouseScrolled",
+	EventTypeWindowResized:       "WindowResized",
+	EventTypeWindowClosed:        "WindowClosed",
+	EventTypeFocusChanged:        "FocusChanged",
+	EventTypeFileDropped:         "FileDropped",
+	EventTypeGamepadConnected:    "GamepadConnected",
+	EventTypeGamepadDisconnected: "GamepadDisconnected",
+}
+
+func (t EventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is produced by the window callbacks while polling events.
+// Use a type switch on the concrete event types to access their data.
+type Event interface {
+	Type() EventType
+}
+
+// EventCallback receives the events produced by the window.
+type EventCallback func(Event)
+
+// KeyPressedEvent .
+type KeyPressedEvent struct {
+	Key Key
+	// Scancode is the platform-specific code of the physical key,
+	// set even when Key is KeyUnknown
+	Scancode int
+	// Mods are the modifiers reported with the event. Whether they
+	// include the modifier key being pressed depends on the platform,
+	// see Window.GetMods
+	Mods   ModifierKey
+	Repeat bool
+}
+
+// Type implements the Event interface.
+func (e *KeyPressedEvent) Type() EventType { return EventTypeKeyPressed }
+
+// KeyReleasedEvent .
+type KeyReleasedEvent struct {
+	Key      Key
+	Scancode int
+	Mods     ModifierKey
+}
+
+// Type implements the Event interface.
+func (e *KeyReleasedEvent) Type() EventType { return EventTypeKeyReleased }
+
+// CharTypedEvent .
+type CharTypedEvent struct {
+	Char rune
+}
+
+// Type implements the Event interface.
+func (e *CharTypedEvent) Type() EventType { return EventTypeCharTyped }
+
+// MouseButtonPressedEvent .
+type MouseButtonPressedEvent struct {
+	Button MouseButton
+	Mods   ModifierKey
+}
+
+// Type implements the Event interface.
+func (e *MouseButtonPressedEvent) Type() EventType { return EventTypeMouseButtonPressed }
+
+// MouseButtonReleasedEvent .
+type MouseButtonReleasedEvent struct {
+	Button MouseButton
+	Mods   ModifierKey
+}
+
+// Type implements the Event interface.
+func (e *MouseButtonReleasedEvent) Type() EventType { return EventTypeMouseButtonReleased }
+
+// MouseMovedEvent .
+type MouseMovedEvent struct {
+	X, Y float64
+}
+
+// Type implements the Event interface.
+func (e *MouseMovedEvent) Type() EventType { return EventTypeMouseMoved }
+
+// MouseScrolledEvent .
+type MouseScrolledEvent struct {
+	XOffset, YOffset float64
+}
+
+// Type implements the Event interface.
+func (e *MouseScrolledEvent) Type() EventType { return EventTypeMouseScrolled }
+
+// WindowResizedEvent .
+type WindowResizedEvent struct {
+	Width, Height int
+}
+
+// Type implements the Event interface.
+func (e *WindowResizedEvent) Type() EventType { return EventTypeWindowResized }
+
+// WindowClosedEvent .
+type WindowClosedEvent struct{}
+
+// Type implements the Event interface.
+func (e *WindowClosedEvent) Type() EventType { return EventTypeWindowClosed }
+
+// FocusChangedEvent .
+type FocusChangedEvent struct {
+	Focused bool
+}
+
+// Type implements the Event interface.
+func (e *FocusChangedEvent) Type() EventType { return EventTypeFocusChanged }
+
+// FileDroppedEvent .
+type FileDroppedEvent struct {
+	Paths []string
+}
+
+// Type implements the Event interface.
+func (e *FileDroppedEvent) Type() EventType { return EventTypeFileDropped }
+
+// GamepadConnectedEvent is produced when a joystick with a gamepad
+// mapping is connected, or when a connected joystick gains one.
+type GamepadConnectedEvent struct {
+	Joystick Joystick
+	Name     string
+}
+
+// Type implements the Event interface.
+func (e *GamepadConnectedEvent) Type() EventType { return EventTypeGamepadConnected }
+
+// GamepadDisconnectedEvent .
+type GamepadDisconnectedEvent struct {
+	Joystick Joystick
+}
+
+// Type implements the Event interface.
+func (e *GamepadDisconnectedEvent) Type() EventType { return EventTypeGamepadDisconnected }
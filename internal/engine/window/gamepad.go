@@ -0,0 +1,250 @@
+package window
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var (
+	defaultGamepadDeadZone = float32(0.15)
+)
+
+// Joystick identifies a connected joystick slot.
+type Joystick int
+
+// glfw joystick mapping
+const (
+	Joystick1    = Joystick(glfw.Joystick1)
+	Joystick2    = Joystick(glfw.Joystick2)
+	Joystick3    = Joystick(glfw.Joystick3)
+	Joystick4    = Joystick(glfw.Joystick4)
+	JoystickLast = Joystick(glfw.JoystickLast)
+)
+
+// GamepadButton is a button of the standard gamepad layout,
+// named after the Xbox controller.
+type GamepadButton int
+
+// glfw gamepad button mapping
+const (
+	GamepadButtonA           = GamepadButton(glfw.ButtonA)
+	GamepadButtonB           = GamepadButton(glfw.ButtonB)
+	GamepadButtonX           = GamepadButton(glfw.ButtonX)
+	GamepadButtonY           = GamepadButton(glfw.ButtonY)
+	GamepadButtonLeftBumper  = GamepadButton(glfw.ButtonLeftBumper)
+	GamepadButtonRightBumper = GamepadButton(glfw.ButtonRightBumper)
+	GamepadButtonBack        = GamepadButton(glfw.ButtonBack)
+	GamepadButtonStart       = GamepadButton(glfw.ButtonStart)
+	GamepadButtonGuide       = GamepadButton(glfw.ButtonGuide)
+	GamepadButtonLeftThumb   = GamepadButton(glfw.ButtonLeftThumb)
+	GamepadButtonRightThumb  = GamepadButton(glfw.ButtonRightThumb)
+	GamepadButtonDpadUp      = GamepadButton(glfw.ButtonDpadUp)
+	GamepadButtonDpadRight   = GamepadButton(glfw.ButtonDpadRight)
+	GamepadButtonDpadDown    = GamepadButton(glfw.ButtonDpadDown)
+	GamepadButtonDpadLeft    = GamepadButton(glfw.ButtonDpadLeft)
+	GamepadButtonLast        = GamepadButton(glfw.ButtonLast)
+)
+
+// GamepadAxis is an axis of the standard gamepad layout. Stick axes range
+// from -1 to 1, the Y axes being positive downward. Trigger axes range
+// from 0, released, to 1, fully pressed.
+type GamepadAxis int
+
+// glfw gamepad axis mapping
+const (
+	GamepadAxisLeftX        = GamepadAxis(glfw.AxisLeftX)
+	GamepadAxisLeftY        = GamepadAxis(glfw.AxisLeftY)
+	GamepadAxisRightX       = GamepadAxis(glfw.AxisRightX)
+	GamepadAxisRightY       = GamepadAxis(glfw.AxisRightY)
+	GamepadAxisLeftTrigger  = GamepadAxis(glfw.AxisLeftTrigger)
+	GamepadAxisRightTrigger = GamepadAxis(glfw.AxisRightTrigger)
+	GamepadAxisLast         = GamepadAxis(glfw.AxisLast)
+)
+
+// gamepad is the state of a joystick with a gamepad mapping.
+type gamepad struct {
+	connected   bool
+	name        string
+	buttons     [GamepadButtonLast + 1]bool
+	prevButtons [GamepadButtonLast + 1]bool
+	axes        [GamepadAxisLast + 1]float32
+}
+
+// initGamepads sets the joystick callback, tracking the joysticks present.
+// Gamepads are reported as connected on the first poll.
+func (w *Window) initGamepads() {
+	for j := Joystick1; j <= JoystickLast; j++ {
+		w.joystickPresent[j] = glfw.Joystick(j).Present()
+	}
+	glfw.SetJoystickCallback(func(joy glfw.Joystick, event glfw.PeripheralEvent) {
+		w.joystickPresent[joy] = event == glfw.Connected
+	})
+}
+
+// pollGamepads reads the state of the gamepads, emitting connection events.
+// Joysticks without a gamepad mapping are ignored until a mapping is added.
+func (w *Window) pollGamepads() {
+	for j := Joystick1; j <= JoystickLast; j++ {
+		var state *glfw.GamepadState
+		if w.joystickPresent[j] {
+			state = glfw.Joystick(j).GetGamepadState()
+		}
+		if state == nil {
+			w.setGamepad(j, nil)
+			continue
+		}
+
+		next := &gamepad{name: w.gamepads[j].name}
+		if !w.gamepads[j].connected {
+			next.name = glfw.Joystick(j).GetGamepadName()
+		}
+		for b := range next.buttons {
+			next.buttons[b] = state.Buttons[b] == glfw.Press
+		}
+		next.axes[GamepadAxisLeftX], next.axes[GamepadAxisLeftY] = applyRadialDeadZone(
+			state.Axes[glfw.AxisLeftX], state.Axes[glfw.AxisLeftY], w.gamepadDeadZone)
+		next.axes[GamepadAxisRightX], next.axes[GamepadAxisRightY] = applyRadialDeadZone(
+			state.Axes[glfw.AxisRightX], state.Axes[glfw.AxisRightY], w.gamepadDeadZone)
+		// triggers are reported from -1 to 1
+		next.axes[GamepadAxisLeftTrigger] = applyDeadZone((state.Axes[glfw.AxisLeftTrigger]+1)/2, w.gamepadDeadZone)
+		next.axes[GamepadAxisRightTrigger] = applyDeadZone((state.Axes[glfw.AxisRightTrigger]+1)/2, w.gamepadDeadZone)
+		w.setGamepad(j, next)
+	}
+}
+
+// setGamepad sets the state of j, nil when disconnected, emitting
+// connection events and keeping the buttons of the previous frame.
+func (w *Window) setGamepad(j Joystick, next *gamepad) {
+	gp := &w.gamepads[j]
+	if next == nil {
+		if gp.connected {
+			*gp = gamepad{}
+			w.emit(&GamepadDisconnectedEvent{Joystick: j})
+		}
+		return
+	}
+	if !gp.connected {
+		w.emit(&GamepadConnectedEvent{Joystick: j, Name: next.name})
+	}
+	prevButtons := gp.buttons
+	*gp = *next
+	gp.connected = true
+	gp.prevButtons = prevButtons
+}
+
+// applyRadialDeadZone zeroes stick positions within the dead zone, and
+// rescales the remaining range so that motion starts from 0.
+func applyRadialDeadZone(x, y, deadZone float32) (float32, float32) {
+	magnitude := float32(math.Hypot(float64(x), float64(y)))
+	if magnitude <= deadZone {
+		return 0, 0
+	}
+	scaled := (magnitude - deadZone) / (1 - deadZone)
+	if scaled > 1 {
+		scaled = 1
+	}
+	return x / magnitude * scaled, y / magnitude * scaled
+}
+
+// applyDeadZone is applyRadialDeadZone for a single positive axis.
+func applyDeadZone(v, deadZone float32) float32 {
+	if v <= deadZone {
+		return 0
+	}
+	return float32(math.Min(float64((v-deadZone)/(1-deadZone)), 1))
+}
+
+// GetGamepads returns the connected gamepads.
+func (w *Window) GetGamepads() []Joystick {
+	return w.state.GetGamepads()
+}
+
+// IsGamepadConnected .
+func (w *Window) IsGamepadConnected(j Joystick) bool {
+	return w.state.IsGamepadConnected(j)
+}
+
+// GetGamepadName returns the name of the gamepad mapping of j.
+func (w *Window) GetGamepadName(j Joystick) string {
+	return w.state.GetGamepadName(j)
+}
+
+// IsGamepadButtonPressed .
+func (w *Window) IsGamepadButtonPressed(j Joystick, b GamepadButton) bool {
+	return w.state.IsGamepadButtonPressed(j, b)
+}
+
+// GetGamepadButtonDown reports whether the button was pressed during the last frame.
+func (w *Window) GetGamepadButtonDown(j Joystick, b GamepadButton) bool {
+	return w.state.GetGamepadButtonDown(j, b)
+}
+
+// GetGamepadButtonUp reports whether the button was released during the last frame.
+func (w *Window) GetGamepadButtonUp(j Joystick, b GamepadButton) bool {
+	return w.state.GetGamepadButtonUp(j, b)
+}
+
+// GetGamepadAxis returns the position of the axis, with the dead zone applied.
+func (w *Window) GetGamepadAxis(j Joystick, a GamepadAxis) float32 {
+	return w.state.GetGamepadAxis(j, a)
+}
+
+// SetGamepadDeadZone sets the fraction of the axes range, in [0, 1),
+// ignored around their rest position.
+func (w *Window) SetGamepadDeadZone(deadZone float32) error {
+	if deadZone < 0 || deadZone >= 1 {
+		return fmt.Errorf("gamepad dead zone must be in [0, 1): %g", deadZone)
+	}
+	w.gamepadDeadZone = deadZone
+	return nil
+}
+
+// GetGamepadDeadZone .
+func (w *Window) GetGamepadDeadZone() float32 {
+	return w.gamepadDeadZone
+}
+
+// UpdateGamepadMappings adds or updates gamepad mappings, using the
+// SDL_GameControllerDB format, one mapping per line. Joysticks which
+// gain a mapping are reported as connected on the next poll. Mappings
+// set before Init are applied when the window is initialized.
+func (w *Window) UpdateGamepadMappings(mappings string) error {
+	if err := validateGamepadMappings(mappings); err != nil {
+		return err
+	}
+	if !w.initialized() {
+		w.gamepadMappings = append(w.gamepadMappings, mappings)
+		return nil
+	}
+	if !glfw.UpdateGamepadMappings(mappings) {
+		return errors.New("error updating gamepad mappings")
+	}
+	return nil
+}
+
+// validateGamepadMappings checks the mappings before handing them to GLFW,
+// whose errors for invalid values would panic on the next GLFW call.
+func validateGamepadMappings(mappings string) error {
+	for i, line := range strings.Split(mappings, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) < 3 || fields[1] == "" {
+			return fmt.Errorf("invalid gamepad mapping on line %d", i+1)
+		}
+		if len(fields[0]) != 32 {
+			return fmt.Errorf("invalid gamepad mapping GUID on line %d: %s", i+1, fields[0])
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil {
+			return fmt.Errorf("invalid gamepad mapping GUID on line %d: %s", i+1, fields[0])
+		}
+	}
+	return nil
+}
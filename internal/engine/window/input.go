@@ -1,6 +1,8 @@
 package window
 
 import (
+	"strings"
+
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
@@ -161,3 +163,44 @@ const (
 	MouseButtonRight  MouseButton = MouseButton(glfw.MouseButtonRight)
 	MouseButtonMiddle MouseButton = MouseButton(glfw.MouseButtonMiddle)
 )
+
+// ModifierKey is a bit field of the modifier keys held down.
+type ModifierKey int
+
+// glfw modifier key mapping
+const (
+	ModShift    = ModifierKey(glfw.ModShift)
+	ModControl  = ModifierKey(glfw.ModControl)
+	ModAlt      = ModifierKey(glfw.ModAlt)
+	ModSuper    = ModifierKey(glfw.ModSuper)
+	ModCapsLock = ModifierKey(glfw.ModCapsLock)
+	ModNumLock  = ModifierKey(glfw.ModNumLock)
+)
+
+var modifierKeyNames = []struct {
+	mod  ModifierKey
+	name string
+}{
+	{ModControl, "Ctrl"},
+	{ModShift, "Shift"},
+	{ModAlt, "Alt"},
+	{ModSuper, "Super"},
+	{ModCapsLock, "CapsLock"},
+	{ModNumLock, "NumLock"},
+}
+
+// Has reports whether every modifier of mods is held down.
+func (m ModifierKey) Has(mods ModifierKey) bool {
+	return m&mods == mods
+}
+
+// String returns the modifiers joined by "+", such as "Ctrl+Shift".
+func (m ModifierKey) String() string {
+	var names []string
+	for _, n := range modifierKeyNames {
+		if m&n.mod != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "+")
+}
@@ -0,0 +1,312 @@
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// buttonState is a bit field of the state of a key or button during a frame.
+type buttonState uint8
+
+const (
+	// held down at the end of the frame
+	stateHeld buttonState = 1 << iota
+	// pressed during the frame
+	statePressed
+	// released during the frame
+	stateReleased
+)
+
+// InputState is a snapshot of the keyboard, mouse and gamepads, built once
+// per frame by Window.PollEvents after processing the pending events. Its
+// queries do not modify it, so any number of readers see the same state
+// for the whole frame, and frame edges, such as GetKeyDown, only last for
+// the frame they happened in. A key pressed and released within the same
+// frame reports both edges.
+type InputState struct {
+	keys         map[Key]buttonState
+	scancodes    map[int]bool
+	mouseButtons map[MouseButton]buttonState
+	mods         ModifierKey
+	chars        []rune
+
+	cursorX, cursorY float64
+	deltaX, deltaY   float64
+	scrollX, scrollY float64
+
+	gamepads [JoystickLast + 1]gamepad
+}
+
+// IsKeyPressed reports whether the key is held down.
+func (s *InputState) IsKeyPressed(key Key) bool {
+	return s.keys[key]&stateHeld != 0
+}
+
+// IsKeyReleased reports whether the key is not held down.
+func (s *InputState) IsKeyReleased(key Key) bool {
+	return !s.IsKeyPressed(key)
+}
+
+// GetKeyDown reports whether the key was pressed during the frame.
+func (s *InputState) GetKeyDown(key Key) bool {
+	return s.keys[key]&statePressed != 0
+}
+
+// GetKeyUp reports whether the key was released during the frame.
+func (s *InputState) GetKeyUp(key Key) bool {
+	return s.keys[key]&stateReleased != 0
+}
+
+// IsScancodePressed reports whether the key with the platform-specific
+// scancode is held down. Scancodes identify physical keys, whatever
+// the keyboard layout.
+func (s *InputState) IsScancodePressed(scancode int) bool {
+	return s.scancodes[scancode]
+}
+
+// GetMods returns the modifier keys held down. Unlike the modifiers
+// of key events, it includes the modifier key just pressed.
+func (s *InputState) GetMods() ModifierKey {
+	return s.mods
+}
+
+// IsModPressed reports whether every modifier of mods is held down,
+// e.g. IsModPressed(ModControl|ModShift).
+func (s *InputState) IsModPressed(mods ModifierKey) bool {
+	return s.mods.Has(mods)
+}
+
+// GetChars returns the unicode characters typed during the frame, in
+// order, for text input.
+func (s *InputState) GetChars() []rune {
+	return s.chars
+}
+
+// IsMouseButtonPressed reports whether the mouse button is held down.
+func (s *InputState) IsMouseButtonPressed(button MouseButton) bool {
+	return s.mouseButtons[button]&stateHeld != 0
+}
+
+// GetMouseButtonDown reports whether the mouse button was pressed during the frame.
+func (s *InputState) GetMouseButtonDown(button MouseButton) bool {
+	return s.mouseButtons[button]&statePressed != 0
+}
+
+// GetMouseButtonUp reports whether the mouse button was released during the frame.
+func (s *InputState) GetMouseButtonUp(button MouseButton) bool {
+	return s.mouseButtons[button]&stateReleased != 0
+}
+
+// GetCursorPos returns the cursor position at the end of the frame.
+func (s *InputState) GetCursorPos() (float64, float64) {
+	return s.cursorX, s.cursorY
+}
+
+// GetCursorDelta returns the cursor motion, in screen coordinates,
+// during the frame.
+func (s *InputState) GetCursorDelta() (float64, float64) {
+	return s.deltaX, s.deltaY
+}
+
+// GetScroll returns the scroll offsets accumulated during the frame.
+// The vertical offset is positive when scrolling up.
+func (s *InputState) GetScroll() (float64, float64) {
+	return s.scrollX, s.scrollY
+}
+
+func (s *InputState) gamepad(j Joystick) *gamepad {
+	if j < Joystick1 || j > JoystickLast || !s.gamepads[j].connected {
+		return nil
+	}
+	return &s.gamepads[j]
+}
+
+// GetGamepads returns the connected gamepads.
+func (s *InputState) GetGamepads() []Joystick {
+	var joysticks []Joystick
+	for j := Joystick1; j <= JoystickLast; j++ {
+		if s.gamepads[j].connected {
+			joysticks = append(joysticks, j)
+		}
+	}
+	return joysticks
+}
+
+// IsGamepadConnected .
+func (s *InputState) IsGamepadConnected(j Joystick) bool {
+	return s.gamepad(j) != nil
+}
+
+// GetGamepadName returns the name of the gamepad mapping of j.
+func (s *InputState) GetGamepadName(j Joystick) string {
+	if gp := s.gamepad(j); gp != nil {
+		return gp.name
+	}
+	return ""
+}
+
+// IsGamepadButtonPressed .
+func (s *InputState) IsGamepadButtonPressed(j Joystick, b GamepadButton) bool {
+	gp := s.gamepad(j)
+	return gp != nil && b >= 0 && b <= GamepadButtonLast && gp.buttons[b]
+}
+
+// GetGamepadButtonDown reports whether the button was pressed during the frame.
+func (s *InputState) GetGamepadButtonDown(j Joystick, b GamepadButton) bool {
+	gp := s.gamepad(j)
+	return gp != nil && b >= 0 && b <= GamepadButtonLast && gp.buttons[b] && !gp.prevButtons[b]
+}
+
+// GetGamepadButtonUp reports whether the button was released during the frame.
+func (s *InputState) GetGamepadButtonUp(j Joystick, b GamepadButton) bool {
+	gp := s.gamepad(j)
+	return gp != nil && b >= 0 && b <= GamepadButtonLast && !gp.buttons[b] && gp.prevButtons[b]
+}
+
+// GetGamepadAxis returns the position of the axis, with the dead zone applied.
+func (s *InputState) GetGamepadAxis(j Joystick, a GamepadAxis) float32 {
+	gp := s.gamepad(j)
+	if gp == nil || a < 0 || a > GamepadAxisLast {
+		return 0
+	}
+	return gp.axes[a]
+}
+
+// inputTracker accumulates the input events of a frame,
+// from which the InputState snapshot is built.
+type inputTracker struct {
+	keys         map[Key]buttonState
+	scancodes    map[int]bool
+	mouseButtons map[MouseButton]buttonState
+	// lock modifiers reported by the last key event
+	lockMods ModifierKey
+	chars    []rune
+
+	// cursor position, updated by the cursor position callback
+	x, y float64
+	// cursor position at the start of the frame
+	frameX, frameY   float64
+	scrollX, scrollY float64
+	// skipDelta drops the motion of the frame, after
+	// the cursor jumped because its mode changed
+	skipDelta bool
+}
+
+func newInputTracker() inputTracker {
+	return inputTracker{
+		keys:         make(map[Key]buttonState),
+		scancodes:    make(map[int]bool),
+		mouseButtons: make(map[MouseButton]buttonState),
+	}
+}
+
+// beginFrame clears the edges and accumulators of the previous frame.
+func (t *inputTracker) beginFrame() {
+	for k, state := range t.keys {
+		if state &= stateHeld; state == 0 {
+			delete(t.keys, k)
+		} else {
+			t.keys[k] = state
+		}
+	}
+	for b, state := range t.mouseButtons {
+		if state &= stateHeld; state == 0 {
+			delete(t.mouseButtons, b)
+		} else {
+			t.mouseButtons[b] = state
+		}
+	}
+	t.chars = nil
+	t.frameX, t.frameY = t.x, t.y
+	t.scrollX, t.scrollY = 0, 0
+}
+
+func (t *inputTracker) onKey(key Key, scancode int, action glfw.Action, mods ModifierKey) {
+	t.lockMods = mods & (ModCapsLock | ModNumLock)
+	switch action {
+	case glfw.Press:
+		t.scancodes[scancode] = true
+		if key != KeyUnknown {
+			t.keys[key] |= stateHeld | statePressed
+		}
+	case glfw.Release:
+		delete(t.scancodes, scancode)
+		if key != KeyUnknown {
+			t.keys[key] = t.keys[key]&^stateHeld | stateReleased
+		}
+	}
+}
+
+func (t *inputTracker) onMouseButton(button MouseButton, action glfw.Action) {
+	switch action {
+	case glfw.Press:
+		t.mouseButtons[button] |= stateHeld | statePressed
+	case glfw.Release:
+		t.mouseButtons[button] = t.mouseButtons[button]&^stateHeld | stateReleased
+	}
+}
+
+func (t *inputTracker) onChar(char rune) {
+	t.chars = append(t.chars, char)
+}
+
+func (t *inputTracker) onCursorPos(x, y float64) {
+	t.x, t.y = x, y
+}
+
+func (t *inputTracker) onScroll(xoff, yoff float64) {
+	t.scrollX += xoff
+	t.scrollY += yoff
+}
+
+// warpCursor sets the cursor position without producing motion.
+func (t *inputTracker) warpCursor(x, y float64) {
+	t.x, t.y = x, y
+	t.skipDelta = true
+}
+
+// snapshot builds the state of the frame.
+func (t *inputTracker) snapshot(gamepads [JoystickLast + 1]gamepad) *InputState {
+	s := &InputState{
+		keys:         make(map[Key]buttonState, len(t.keys)),
+		scancodes:    make(map[int]bool, len(t.scancodes)),
+		mouseButtons: make(map[MouseButton]buttonState, len(t.mouseButtons)),
+		mods:         t.lockMods,
+		chars:        t.chars,
+		cursorX:      t.x,
+		cursorY:      t.y,
+		deltaX:       t.x - t.frameX,
+		deltaY:       t.y - t.frameY,
+		scrollX:      t.scrollX,
+		scrollY:      t.scrollY,
+		gamepads:     gamepads,
+	}
+	if t.skipDelta {
+		t.skipDelta = false
+		s.deltaX, s.deltaY = 0, 0
+	}
+	for k, state := range t.keys {
+		s.keys[k] = state
+	}
+	for sc := range t.scancodes {
+		s.scancodes[sc] = true
+	}
+	for b, state := range t.mouseButtons {
+		s.mouseButtons[b] = state
+	}
+
+	modKeys := []struct {
+		left, right Key
+		mod         ModifierKey
+	}{
+		{KeyLeftShift, KeyRightShift, ModShift},
+		{KeyLeftControl, KeyRightControl, ModControl},
+		{KeyLeftAlt, KeyRightAlt, ModAlt},
+		{KeyLeftSuper, KeyRightSuper, ModSuper},
+	}
+	for _, m := range modKeys {
+		if s.IsKeyPressed(m.left) || s.IsKeyPressed(m.right) {
+			s.mods |= m.mod
+		}
+	}
+	return s
+}
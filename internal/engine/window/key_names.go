@@ -0,0 +1,81 @@
+package window
+
+import "fmt"
+
+// keyNames holds the names of the keys using the US layout, used when the
+// platform does not provide a layout-specific name.
+var keyNames = map[Key]string{
+	KeySpace:        "Space",
+	KeyApostrophe:   "'",
+	KeyComma:        ",",
+	KeyMinus:        "-",
+	KeyPeriod:       ".",
+	KeySlash:        "/",
+	KeySemicolon:    ";",
+	KeyEqual:        "=",
+	KeyLeftBracket:  "[",
+	KeyBackslash:    "\\",
+	KeyRightBracket: "]",
+	KeyGraveAccent:  "`",
+	KeyWorld1:       "World1",
+	KeyWorld2:       "World2",
+	KeyEscape:       "Escape",
+	KeyEnter:        "Enter",
+	KeyTab:          "Tab",
+	KeyBackspace:    "Backspace",
+	KeyInsert:       "Insert",
+	KeyDelete:       "Delete",
+	KeyRight:        "Right",
+	KeyLeft:         "Left",
+	KeyDown:         "Down",
+	KeyUp:           "Up",
+	KeyPageUp:       "PageUp",
+	KeyPageDown:     "PageDown",
+	KeyHome:         "Home",
+	KeyEnd:          "End",
+	KeyCapsLock:     "CapsLock",
+	KeyScrollLock:   "ScrollLock",
+	KeyNumLock:      "NumLock",
+	KeyPrintScreen:  "PrintScreen",
+	KeyPause:        "Pause",
+	KeyKPDecimal:    "KP.",
+	KeyKPDivide:     "KP/",
+	KeyKPMultiply:   "KP*",
+	KeyKPSubtract:   "KP-",
+	KeyKPAdd:        "KP+",
+	KeyKPEnter:      "KPEnter",
+	KeyKPEqual:      "KP=",
+	KeyLeftShift:    "LeftShift",
+	KeyLeftControl:  "LeftControl",
+	KeyLeftAlt:      "LeftAlt",
+	KeyLeftSuper:    "LeftSuper",
+	KeyRightShift:   "RightShift",
+	KeyRightControl: "RightControl",
+	KeyRightAlt:     "RightAlt",
+	KeyRightSuper:   "RightSuper",
+	KeyMenu:         "Menu",
+}
+
+func init() {
+	for k := Key0; k <= Key9; k++ {
+		keyNames[k] = string(rune('0' + k - Key0))
+	}
+	for k := KeyA; k <= KeyZ; k++ {
+		keyNames[k] = string(rune('A' + k - KeyA))
+	}
+	for k := KeyF1; k <= KeyF25; k++ {
+		keyNames[k] = fmt.Sprintf("F%d", k-KeyF1+1)
+	}
+	for k := KeyKP0; k <= KeyKP9; k++ {
+		keyNames[k] = fmt.Sprintf("KP%d", k-KeyKP0)
+	}
+}
+
+// String returns the name of the key using the US layout.
+// Use Window.GetKeyName for the name using the current layout.
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Key(%d)", int(k))
+}
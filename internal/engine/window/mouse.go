@@ -0,0 +1,207 @@
+package window
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// CursorMode controls the visibility and motion of the cursor.
+type CursorMode int
+
+// Cursor modes
+const (
+	// CursorNormal shows the cursor, which moves freely.
+	CursorNormal CursorMode = iota
+	// CursorHidden hides the cursor while over the window.
+	CursorHidden
+	// CursorDisabled hides and locks the cursor to the window, providing
+	// unlimited motion through GetCursorDelta. This is what FPS cameras use.
+	CursorDisabled
+)
+
+func (m CursorMode) String() string {
+	switch m {
+	case CursorNormal:
+		return "normal"
+	case CursorHidden:
+		return "hidden"
+	case CursorDisabled:
+		return "disabled"
+	}
+	return fmt.Sprintf("CursorMode(%d)", int(m))
+}
+
+// StandardCursor is a cursor shape provided by the platform.
+type StandardCursor int
+
+// glfw standard cursor mapping
+const (
+	ArrowCursor     = StandardCursor(glfw.ArrowCursor)
+	IBeamCursor     = StandardCursor(glfw.IBeamCursor)
+	CrosshairCursor = StandardCursor(glfw.CrosshairCursor)
+	HandCursor      = StandardCursor(glfw.HandCursor)
+	HResizeCursor   = StandardCursor(glfw.HResizeCursor)
+	VResizeCursor   = StandardCursor(glfw.VResizeCursor)
+)
+
+// mouseState holds the cursor settings.
+type mouseState struct {
+	mode   CursorMode
+	raw    bool
+	cursor *glfw.Cursor
+}
+
+// PollEvents processes the pending events, calling the event callback, and
+// reads the gamepads state, then builds the input snapshot of the frame.
+// It must be called once per frame on the main thread. Event callbacks
+// run before the snapshot is built, and see the previous one.
+func (w *Window) PollEvents() {
+	w.input.beginFrame()
+	w.beginCapture()
+	glfw.PollEvents()
+	w.pollGamepads()
+	w.state = w.input.snapshot(w.gamepads)
+	w.endCapture()
+}
+
+// GetMouseButtonDown reports whether the mouse button was pressed during the last frame.
+func (w *Window) GetMouseButtonDown(button MouseButton) bool {
+	return w.state.GetMouseButtonDown(button)
+}
+
+// GetMouseButtonUp reports whether the mouse button was released during the last frame.
+func (w *Window) GetMouseButtonUp(button MouseButton) bool {
+	return w.state.GetMouseButtonUp(button)
+}
+
+// GetCursorDelta returns the cursor motion, in screen coordinates,
+// during the last frame.
+func (w *Window) GetCursorDelta() (float64, float64) {
+	return w.state.GetCursorDelta()
+}
+
+// GetScroll returns the scroll offsets accumulated during the last frame.
+// The vertical offset is positive when scrolling up.
+func (w *Window) GetScroll() (float64, float64) {
+	return w.state.GetScroll()
+}
+
+// SetCursorMode sets the cursor mode, applied by Init when called
+// before. While replaying, the cursor jumps it produces are replayed
+// from the recording instead.
+func (w *Window) SetCursorMode(mode CursorMode) {
+	switch mode {
+	case CursorHidden, CursorDisabled:
+	default:
+		mode = CursorNormal
+	}
+	w.mouse.mode = mode
+	if !w.initialized() {
+		return
+	}
+	w.applyCursorMode()
+	if !w.liveInput() {
+		return
+	}
+	x, y := w.window.GetCursorPos()
+	w.input.warpCursor(x, y)
+	if w.replay.recording {
+		w.replay.warp = &CursorPos{X: x, Y: y}
+	}
+}
+
+// applyCursorMode sets the cursor mode of the platform window.
+func (w *Window) applyCursorMode() {
+	switch w.mouse.mode {
+	case CursorHidden:
+		w.window.SetInputMode(glfw.CursorMode, glfw.CursorHidden)
+	case CursorDisabled:
+		w.window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	default:
+		w.window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	}
+}
+
+// GetCursorMode .
+func (w *Window) GetCursorMode() CursorMode {
+	return w.mouse.mode
+}
+
+// SetRawMouseMotion enables unscaled and unaccelerated mouse motion while
+// the cursor is disabled, when supported by the platform. It returns
+// whether raw mouse motion is enabled. When called before Init, the
+// setting is applied by Init and the platform support checked then.
+func (w *Window) SetRawMouseMotion(enabled bool) bool {
+	if !w.initialized() {
+		w.mouse.raw = enabled
+		return enabled
+	}
+	if enabled && !glfw.RawMouseMotionSupported() {
+		w.mouse.raw = false
+		return false
+	}
+	if enabled {
+		w.window.SetInputMode(glfw.RawMouseMotion, glfw.True)
+	} else {
+		w.window.SetInputMode(glfw.RawMouseMotion, glfw.False)
+	}
+	w.mouse.raw = enabled
+	return enabled
+}
+
+// IsRawMouseMotion .
+func (w *Window) IsRawMouseMotion() bool {
+	return w.mouse.raw
+}
+
+// SetCursorImage sets the cursor shown over the window to img, the
+// hotspot xhot, yhot being the pixel at the cursor position. It returns
+// ErrNotInitialized when called before Init.
+func (w *Window) SetCursorImage(img image.Image, xhot, yhot int) error {
+	if !w.initialized() {
+		return ErrNotInitialized
+	}
+	if img == nil {
+		return fmt.Errorf("cursor image is nil")
+	}
+	cursor := glfw.CreateCursor(img, xhot, yhot)
+	if cursor == nil {
+		return fmt.Errorf("error creating cursor")
+	}
+	w.setCursor(cursor)
+	return nil
+}
+
+// SetStandardCursor sets the cursor shown over the window to a shape
+// provided by the platform. It returns ErrNotInitialized when called
+// before Init.
+func (w *Window) SetStandardCursor(shape StandardCursor) error {
+	if !w.initialized() {
+		return ErrNotInitialized
+	}
+	cursor := glfw.CreateStandardCursor(glfw.StandardCursor(shape))
+	if cursor == nil {
+		return fmt.Errorf("error creating standard cursor")
+	}
+	w.setCursor(cursor)
+	return nil
+}
+
+// ResetCursor restores the default cursor.
+func (w *Window) ResetCursor() {
+	if !w.initialized() {
+		return
+	}
+	w.setCursor(nil)
+}
+
+// setCursor sets the cursor, destroying the previous custom cursor.
+func (w *Window) setCursor(cursor *glfw.Cursor) {
+	w.window.SetCursor(cursor)
+	if w.mouse.cursor != nil {
+		w.mouse.cursor.Destroy()
+	}
+	w.mouse.cursor = cursor
+}
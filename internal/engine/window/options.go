@@ -19,3 +19,26 @@ func WithTitleOption(title string) Option {
 		return nil
 	}
 }
+
+// WithVSyncOption .
+func WithVSyncOption(mode VSyncMode) Option {
+	return func(w *Window) error {
+		w.vsync = mode
+		return nil
+	}
+}
+
+// WithGamepadMappingsOption adds gamepad mappings in the
+// SDL_GameControllerDB format, one mapping per line.
+func WithGamepadMappingsOption(mappings string) Option {
+	return func(w *Window) error {
+		return w.UpdateGamepadMappings(mappings)
+	}
+}
+
+// WithGamepadDeadZoneOption .
+func WithGamepadDeadZoneOption(deadZone float32) Option {
+	return func(w *Window) error {
+		return w.SetGamepadDeadZone(deadZone)
+	}
+}
@@ -0,0 +1,184 @@
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// InputFrame is the input received by the window during a frame: the input
+// events in the order they were received, the focus and the gamepads. It is
+// recorded by PollEvents when recording, and played back by ReplayEvents.
+// Window events which are not input, such as resizing, are not recorded.
+type InputFrame struct {
+	Events []Event
+	// Warp is the cursor position set before the frame, by a change of
+	// cursor mode or when recording starts, nil if the cursor did not jump
+	Warp *CursorPos
+	// Focused reports whether the window had focus at the end of the frame
+	Focused bool
+	// Gamepads holds the state of the connected gamepads
+	Gamepads []GamepadFrame
+}
+
+// CursorPos is a cursor position, in screen coordinates.
+type CursorPos struct {
+	X, Y float64
+}
+
+// GamepadFrame is the state of a connected gamepad, with the dead zone applied.
+type GamepadFrame struct {
+	Joystick Joystick
+	Name     string
+	Buttons  [GamepadButtonLast + 1]bool
+	Axes     [GamepadAxisLast + 1]float32
+}
+
+// replayState holds the recording and replay state of the window.
+type replayState struct {
+	recording bool
+	// capture collects the input of the frame being polled,
+	// recorded is the input of the last polled frame
+	capture  *InputFrame
+	recorded *InputFrame
+	// warp is the cursor jump to record with the next frame
+	warp *CursorPos
+
+	// replaying is set once a frame was replayed, the input of the
+	// platform being ignored from then on
+	replaying bool
+	focused   bool
+}
+
+// SetInputRecording enables recording the input of the frames polled by
+// PollEvents, each being available from RecordedInput until the next poll.
+// The first frame recorded starts from the current cursor position.
+func (w *Window) SetInputRecording(enabled bool) {
+	if enabled && !w.replay.recording {
+		w.replay.warp = &CursorPos{X: w.input.x, Y: w.input.y}
+	}
+	w.replay.recording = enabled
+	w.replay.recorded = nil
+}
+
+// IsInputRecording .
+func (w *Window) IsInputRecording() bool {
+	return w.replay.recording
+}
+
+// RecordedInput returns the input of the last polled frame,
+// or nil when not recording.
+func (w *Window) RecordedInput() *InputFrame {
+	return w.replay.recorded
+}
+
+// IsReplaying reports whether the input comes from ReplayEvents.
+func (w *Window) IsReplaying() bool {
+	return w.replay.replaying
+}
+
+// beginCapture starts recording the input of the frame being polled.
+func (w *Window) beginCapture() {
+	if !w.replay.recording {
+		return
+	}
+	w.replay.capture = &InputFrame{Warp: w.replay.warp}
+	w.replay.warp = nil
+}
+
+// endCapture completes the input of the polled frame.
+func (w *Window) endCapture() {
+	f := w.replay.capture
+	if f == nil {
+		return
+	}
+	f.Focused = w.IsFocused()
+	for j := Joystick1; j <= JoystickLast; j++ {
+		if gp := w.gamepads[j]; gp.connected {
+			f.Gamepads = append(f.Gamepads, GamepadFrame{
+				Joystick: j,
+				Name:     gp.name,
+				Buttons:  gp.buttons,
+				Axes:     gp.axes,
+			})
+		}
+	}
+	w.replay.capture = nil
+	w.replay.recorded = f
+}
+
+// captureEvent records the input events emitted while polling.
+func (w *Window) captureEvent(e Event) {
+	if w.replay.capture == nil {
+		return
+	}
+	switch e.(type) {
+	case *KeyPressedEvent, *KeyReleasedEvent, *CharTypedEvent,
+		*MouseButtonPressedEvent, *MouseButtonReleasedEvent,
+		*MouseMovedEvent, *MouseScrolledEvent, *FocusChangedEvent:
+		w.replay.capture.Events = append(w.replay.capture.Events, e)
+	}
+}
+
+// liveInput reports whether the input callbacks of the platform are used.
+func (w *Window) liveInput() bool {
+	return !w.replay.replaying
+}
+
+// ReplayEvents is PollEvents for a recorded frame: the recorded events are
+// processed in order, calling the event callback, then the input snapshot
+// of the frame is built. Once called, the input of the platform is ignored
+// and the window reports the recorded focus. It does not require the window
+// to be initialized, so that recordings can be replayed headlessly; when it
+// is, the pending events of the platform are still processed to keep the
+// window responsive.
+func (w *Window) ReplayEvents(f *InputFrame) {
+	w.replay.replaying = true
+	w.input.beginFrame()
+	if w.initialized() {
+		glfw.PollEvents()
+	}
+
+	if f.Warp != nil {
+		w.input.warpCursor(f.Warp.X, f.Warp.Y)
+	}
+	for _, e := range f.Events {
+		w.replayEvent(e)
+	}
+	w.replay.focused = f.Focused
+
+	var next [JoystickLast + 1]*gamepad
+	for _, g := range f.Gamepads {
+		if g.Joystick >= Joystick1 && g.Joystick <= JoystickLast {
+			next[g.Joystick] = &gamepad{name: g.Name, buttons: g.Buttons, axes: g.Axes}
+		}
+	}
+	for j := Joystick1; j <= JoystickLast; j++ {
+		w.setGamepad(j, next[j])
+	}
+
+	w.state = w.input.snapshot(w.gamepads)
+}
+
+// replayEvent updates the input state from e as its callback would, then emits it.
+func (w *Window) replayEvent(e Event) {
+	switch e := e.(type) {
+	case *KeyPressedEvent:
+		action := glfw.Press
+		if e.Repeat {
+			action = glfw.Repeat
+		}
+		w.input.onKey(e.Key, e.Scancode, action, e.Mods)
+	case *KeyReleasedEvent:
+		w.input.onKey(e.Key, e.Scancode, glfw.Release, e.Mods)
+	case *CharTypedEvent:
+		w.input.onChar(e.Char)
+	case *MouseButtonPressedEvent:
+		w.input.onMouseButton(e.Button, glfw.Press)
+	case *MouseButtonReleasedEvent:
+		w.input.onMouseButton(e.Button, glfw.Release)
+	case *MouseMovedEvent:
+		w.input.onCursorPos(e.X, e.Y)
+	case *MouseScrolledEvent:
+		w.input.onScroll(e.XOffset, e.YOffset)
+	}
+	w.emit(e)
+}
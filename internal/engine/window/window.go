@@ -1,13 +1,19 @@
 package window
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
+// Errors
+var (
+	ErrNotInitialized = errors.New("window not initialized")
+)
+
 var (
 	glfwMajorVersion            = 4
 	glfwMinorVersion            = 6
@@ -20,17 +26,59 @@ var (
 	defaultWindowHeight    = 768
 	defaultWindowTitle     = "Application"
 	defaultWindowResizable = true
+	defaultWindowVSync     = VSyncOff
+)
+
+// VSyncMode controls how buffer swaps are synchronized with the monitor refresh.
+type VSyncMode int
+
+// VSync modes
+const (
+	// VSyncOff swaps buffers immediately.
+	VSyncOff VSyncMode = iota
+	// VSyncOn waits for the vertical blank before swapping buffers.
+	VSyncOn
+	// VSyncAdaptive waits for the vertical blank, unless the frame is late,
+	// in which case buffers are swapped immediately. It falls back to
+	// VSyncOn when the swap control tear extension is not supported.
+	VSyncAdaptive
 )
 
+func (m VSyncMode) String() string {
+	switch m {
+	case VSyncOff:
+		return "off"
+	case VSyncOn:
+		return "on"
+	case VSyncAdaptive:
+		return "adaptive"
+	}
+	return fmt.Sprintf("VSyncMode(%d)", int(m))
+}
+
 // Window .
 type Window struct {
 	width     int
 	height    int
 	title     string
 	resizable bool
+	vsync     VSyncMode
+
+	// input accumulates the input events of the frame being polled,
+	// state is the snapshot of the last polled frame
+	input inputTracker
+	state *InputState
+
+	mouse  mouseState
+	replay replayState
 
-	keyPressed  map[Key]bool
-	keyReleased map[Key]bool
+	joystickPresent [JoystickLast + 1]bool
+	gamepads        [JoystickLast + 1]gamepad
+	gamepadDeadZone float32
+	// gamepad mappings added before Init
+	gamepadMappings []string
+
+	eventCallback EventCallback
 
 	window *glfw.Window
 }
@@ -38,14 +86,17 @@ type Window struct {
 // New .
 func New(options ...Option) (*Window, error) {
 	window := &Window{
-		width:       defaultWindowWidth,
-		height:      defaultWindowHeight,
-		title:       defaultWindowTitle,
-		resizable:   defaultWindowResizable,
-		keyPressed:  make(map[Key]bool),
-		keyReleased: make(map[Key]bool),
+		width:           defaultWindowWidth,
+		height:          defaultWindowHeight,
+		title:           defaultWindowTitle,
+		resizable:       defaultWindowResizable,
+		vsync:           defaultWindowVSync,
+		input:           newInputTracker(),
+		gamepadDeadZone: defaultGamepadDeadZone,
 	}
 
+	window.state = window.input.snapshot(window.gamepads)
+
 	for _, opt := range options {
 		if err := opt(window); err != nil {
 			return nil, err
@@ -85,20 +136,81 @@ func (w *Window) Init() error {
 	w.window = window
 	w.window.MakeContextCurrent()
 
+	// the framebuffer is larger than the window on high DPI displays
+	w.width, w.height = w.window.GetFramebufferSize()
+
+	// report the state of caps lock and num lock in modifiers
+	w.window.SetInputMode(glfw.LockKeyMods, glfw.True)
+
 	w.window.SetKeyCallback(func(ww *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if !w.liveInput() {
+			return
+		}
 		mKey := Key(key)
+		mMods := ModifierKey(mods)
+		w.input.onKey(mKey, scancode, action, mMods)
+		switch action {
+		case glfw.Press:
+			w.emit(&KeyPressedEvent{Key: mKey, Scancode: scancode, Mods: mMods})
+		case glfw.Repeat:
+			w.emit(&KeyPressedEvent{Key: mKey, Scancode: scancode, Mods: mMods, Repeat: true})
+		case glfw.Release:
+			w.emit(&KeyReleasedEvent{Key: mKey, Scancode: scancode, Mods: mMods})
+		}
+	})
+
+	// produce events from the remaining callbacks, the input
+	// callbacks being ignored while replaying a recording
+	w.window.SetCharCallback(func(ww *glfw.Window, char rune) {
+		if !w.liveInput() {
+			return
+		}
+		w.input.onChar(char)
+		w.emit(&CharTypedEvent{Char: char})
+	})
+	w.window.SetMouseButtonCallback(func(ww *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		if !w.liveInput() {
+			return
+		}
+		w.input.onMouseButton(MouseButton(button), action)
 		switch action {
 		case glfw.Press:
-			delete(w.keyReleased, mKey)
-			if _, ok := w.keyPressed[mKey]; !ok {
-				w.keyPressed[mKey] = false
-			}
+			w.emit(&MouseButtonPressedEvent{Button: MouseButton(button), Mods: ModifierKey(mods)})
 		case glfw.Release:
-			delete(w.keyPressed, mKey)
-			if _, ok := w.keyReleased[mKey]; !ok {
-				w.keyReleased[mKey] = false
-			}
+			w.emit(&MouseButtonReleasedEvent{Button: MouseButton(button), Mods: ModifierKey(mods)})
+		}
+	})
+	w.window.SetCursorPosCallback(func(ww *glfw.Window, xpos float64, ypos float64) {
+		if !w.liveInput() {
+			return
 		}
+		w.input.onCursorPos(xpos, ypos)
+		w.emit(&MouseMovedEvent{X: xpos, Y: ypos})
+	})
+	w.window.SetScrollCallback(func(ww *glfw.Window, xoff float64, yoff float64) {
+		if !w.liveInput() {
+			return
+		}
+		w.input.onScroll(xoff, yoff)
+		w.emit(&MouseScrolledEvent{XOffset: xoff, YOffset: yoff})
+	})
+	// apply the cursor settings set before Init
+	w.applyCursorMode()
+	if w.mouse.raw {
+		w.SetRawMouseMotion(true)
+	}
+	w.input.warpCursor(w.window.GetCursorPos())
+	w.window.SetCloseCallback(func(ww *glfw.Window) {
+		w.emit(&WindowClosedEvent{})
+	})
+	w.window.SetFocusCallback(func(ww *glfw.Window, focused bool) {
+		if !w.liveInput() {
+			return
+		}
+		w.emit(&FocusChangedEvent{Focused: focused})
+	})
+	w.window.SetDropCallback(func(ww *glfw.Window, names []string) {
+		w.emit(&FileDroppedEvent{Paths: names})
 	})
 
 	// set window resize callback
@@ -107,24 +219,80 @@ func (w *Window) Init() error {
 			gl.Viewport(0, 0, int32(width), int32(height))
 			w.width = width
 			w.height = height
+			w.emit(&WindowResizedEvent{Width: width, Height: height})
 		})
 	}
 
-	// set vsync (synchronize buffer swap with monitor refresh rate)
-	if os.Getenv("VSYNC") == "true" {
-		glfw.SwapInterval(1)
-	} else {
-		glfw.SwapInterval(0)
+	for _, mappings := range w.gamepadMappings {
+		if !glfw.UpdateGamepadMappings(mappings) {
+			return errors.New("error updating gamepad mappings")
+		}
 	}
+	w.gamepadMappings = nil
+	w.initGamepads()
+
+	w.applyVSync()
 
 	return nil
 }
 
-// GetSize .
+// SetEventCallback sets the callback receiving the events produced
+// while polling events.
+func (w *Window) SetEventCallback(fn EventCallback) {
+	w.eventCallback = fn
+}
+
+func (w *Window) emit(e Event) {
+	w.captureEvent(e)
+	if w.eventCallback != nil {
+		w.eventCallback(e)
+	}
+}
+
+// SetVSync sets the vsync mode. It can be changed at runtime.
+func (w *Window) SetVSync(mode VSyncMode) {
+	w.vsync = mode
+	if w.initialized() {
+		w.applyVSync()
+	}
+}
+
+// VSync .
+func (w *Window) VSync() VSyncMode {
+	return w.vsync
+}
+
+// applyVSync sets the swap interval of the current context.
+func (w *Window) applyVSync() {
+	switch w.vsync {
+	case VSyncOn:
+		glfw.SwapInterval(1)
+	case VSyncAdaptive:
+		if glfw.ExtensionSupported("WGL_EXT_swap_control_tear") || glfw.ExtensionSupported("GLX_EXT_swap_control_tear") {
+			glfw.SwapInterval(-1)
+		} else {
+			glfw.SwapInterval(1)
+		}
+	default:
+		glfw.SwapInterval(0)
+	}
+}
+
+// GetSize returns the size of the framebuffer, in pixels.
 func (w *Window) GetSize() (int, int) {
 	return w.width, w.height
 }
 
+// GetScreenSize returns the size of the window in screen coordinates, in
+// which the cursor positions are reported. On high DPI displays, it is
+// smaller than the framebuffer size returned by GetSize.
+func (w *Window) GetScreenSize() (int, int) {
+	if !w.initialized() {
+		return w.width, w.height
+	}
+	return w.window.GetSize()
+}
+
 // SetSize .
 func (w *Window) SetSize(width, height int) {
 	if w.initialized() {
@@ -139,53 +307,111 @@ func (w *Window) ShouldClose() bool {
 	return w.window.ShouldClose()
 }
 
-// IsFocused .
+// IsIconified .
+func (w *Window) IsIconified() bool {
+	if !w.initialized() {
+		return false
+	}
+	return w.window.GetAttrib(glfw.Iconified) == glfw.True
+}
+
+// IsFocused reports whether the window has focus,
+// or had it in the frame being replayed.
 func (w *Window) IsFocused() bool {
+	if w.replay.replaying {
+		return w.replay.focused
+	}
 	return w.window.GetAttrib(glfw.Focused) == glfw.True
 }
 
+// Input returns the input snapshot of the last polled frame.
+func (w *Window) Input() *InputState {
+	return w.state
+}
+
+// GetKeyDown reports whether the key was pressed during the last frame.
 func (w *Window) GetKeyDown(key Key) bool {
-	requested, ok := w.keyPressed[key]
-	if ok && !requested {
-		w.keyPressed[key] = true
-		return true
-	}
-	return false
+	return w.state.GetKeyDown(key)
 }
 
+// GetKeyUp reports whether the key was released during the last frame.
 func (w *Window) GetKeyUp(key Key) bool {
-	requested, ok := w.keyReleased[key]
-	if ok && !requested {
-		w.keyReleased[key] = true
-		return true
-	}
-	return false
+	return w.state.GetKeyUp(key)
 }
 
 // IsKeyPressed .
 func (w *Window) IsKeyPressed(key Key) bool {
-	_, ok := w.keyPressed[key]
-	return ok
+	return w.state.IsKeyPressed(key)
 }
 
 // IsKeyReleased .
 func (w *Window) IsKeyReleased(key Key) bool {
-	_, ok := w.keyReleased[key]
-	return ok
+	return w.state.IsKeyReleased(key)
+}
+
+// GetMods returns the modifier keys held down. Unlike the modifiers
+// of key events, it includes the modifier key just pressed.
+func (w *Window) GetMods() ModifierKey {
+	return w.state.GetMods()
+}
+
+// IsModPressed reports whether every modifier of mods is held down,
+// e.g. IsModPressed(ModControl|ModShift).
+func (w *Window) IsModPressed(mods ModifierKey) bool {
+	return w.state.IsModPressed(mods)
+}
+
+// IsScancodePressed reports whether the key with the platform-specific
+// scancode is held down. Scancodes identify physical keys, whatever
+// the keyboard layout.
+func (w *Window) IsScancodePressed(scancode int) bool {
+	return w.state.IsScancodePressed(scancode)
+}
+
+// GetKeyScancode returns the scancode of the key, or -1
+// when the key does not exist on the keyboard.
+func (w *Window) GetKeyScancode(key Key) int {
+	return glfw.GetKeyScancode(glfw.Key(key))
+}
+
+// GetKeyName returns the name of the printable key in the current keyboard
+// layout, such as "Z" for KeyY on a german layout. It falls back to the
+// US layout name of the key for non-printable keys.
+func (w *Window) GetKeyName(key Key) string {
+	if name := glfw.GetKeyName(glfw.Key(key), 0); name != "" {
+		return strings.ToUpper(name)
+	}
+	return key.String()
+}
+
+// GetScancodeName returns the name of the printable key
+// with scancode in the current keyboard layout, if any.
+func (w *Window) GetScancodeName(scancode int) string {
+	return strings.ToUpper(glfw.GetKeyName(glfw.KeyUnknown, scancode))
+}
+
+// GetChars returns the unicode characters typed during the last frame, in
+// order, for text input. Characters are also produced as CharTypedEvent.
+func (w *Window) GetChars() []rune {
+	return w.state.GetChars()
 }
 
 // IsMouseButtonPressed .
 func (w *Window) IsMouseButtonPressed(m MouseButton) bool {
-	return w.window.GetMouseButton(glfw.MouseButton(m)) == glfw.Press
+	return w.state.IsMouseButtonPressed(m)
 }
 
 // GetCursorPos .
 func (w *Window) GetCursorPos() (float64, float64) {
-	return w.window.GetCursorPos()
+	return w.state.GetCursorPos()
 }
 
 // Close .
 func (w *Window) Close() error {
+	if w.mouse.cursor != nil {
+		w.mouse.cursor.Destroy()
+		w.mouse.cursor = nil
+	}
 	glfw.Terminate()
 	w.window = nil
 	return nil
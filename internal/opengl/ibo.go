@@ -1,6 +1,7 @@
 package opengl
 
 import (
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
@@ -28,6 +29,7 @@ func NewIBO(count int) *IBO {
 	ibo.Bind()
 	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, 4*count, nil, gl.DYNAMIC_DRAW)
 	ibo.Unbind()
+	atomic.AddInt64(&liveIBOs, 1)
 
 	return ibo
 }
@@ -0,0 +1,36 @@
+package opengl
+
+import "sync/atomic"
+
+// live GL resources created through this package
+var (
+	liveTextures       int64
+	liveVBOs           int64
+	liveIBOs           int64
+	liveVAOs           int64
+	liveShaderPrograms int64
+)
+
+// Resources are the counts of live GL resources created through this package.
+// Every live texture uses a texture slot, MaxTextureSlots being the limit.
+type Resources struct {
+	Textures        int64 `json:"textures"`
+	MaxTextureSlots int64 `json:"maxTextureSlots"`
+	VBOs            int64 `json:"vbos"`
+	IBOs            int64 `json:"ibos"`
+	VAOs            int64 `json:"vaos"`
+	ShaderPrograms  int64 `json:"shaderPrograms"`
+}
+
+// LiveResources returns the counts of live GL resources.
+// It is safe to call from any goroutine.
+func LiveResources() Resources {
+	return Resources{
+		Textures:        atomic.LoadInt64(&liveTextures),
+		MaxTextureSlots: int64(maxTextures),
+		VBOs:            atomic.LoadInt64(&liveVBOs),
+		IBOs:            atomic.LoadInt64(&liveIBOs),
+		VAOs:            atomic.LoadInt64(&liveVAOs),
+		ShaderPrograms:  atomic.LoadInt64(&liveShaderPrograms),
+	}
+}
@@ -3,6 +3,7 @@ package opengl
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
 )
@@ -46,9 +47,20 @@ func NewShaderProgram(vertexShaderSource, fragmentShaderSource string) (*ShaderP
 	}
 
 	shaderProgram := &ShaderProgram{id: shaderProgramID}
+	atomic.AddInt64(&liveShaderPrograms, 1)
 	return shaderProgram, nil
 }
 
+// Delete frees the shader program. It must not be used afterwards.
+func (s *ShaderProgram) Delete() {
+	if s.id == 0 {
+		return
+	}
+	gl.DeleteProgram(s.id)
+	s.id = 0
+	atomic.AddInt64(&liveShaderPrograms, -1)
+}
+
 // Bind .
 func (s *ShaderProgram) Bind() {
 	gl.UseProgram(s.id)
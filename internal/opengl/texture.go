@@ -3,7 +3,9 @@ package opengl
 import (
 	"fmt"
 	"image"
+	"io"
 	"os"
+	"sync/atomic"
 
 	// need to initialize each image type
 	// that could be used in NewTexture
@@ -15,8 +17,10 @@ import (
 )
 
 var (
-	maxTextures  uint32 = 32
-	textureCount uint32 = 0
+	maxTextures uint32 = 32
+	// textureSlots tracks the texture units in use,
+	// freed slots are reused by new textures
+	textureSlots [32]bool
 )
 
 type Texture interface {
@@ -25,6 +29,17 @@ type Texture interface {
 	Unit() uint32
 }
 
+// Deleter is implemented by GL resources which can be freed.
+type Deleter interface {
+	Delete()
+}
+
+// MutableTexture is a Texture whose content can be replaced after creation.
+type MutableTexture interface {
+	Texture
+	SetNRGBA(*image.NRGBA)
+}
+
 type texture struct {
 	id    uint32
 	index uint32
@@ -33,17 +48,20 @@ type texture struct {
 
 // Newtexture .
 func NewNRGBATexture(filepath string) (*texture, error) {
-	rgba, err := rgbaFromFile(filepath)
+	rgba, err := LoadNRGBA(filepath)
 	if err != nil {
 		return nil, err
 	}
+	return NewNRGBATextureFromImage(rgba)
+}
 
-	if textureCount >= maxTextures {
+// NewNRGBATextureFromImage creates a texture from an image already in memory.
+// The image is uploaded as-is: its first row maps to the texture coordinate v=0.
+func NewNRGBATextureFromImage(rgba *image.NRGBA) (*texture, error) {
+	slot, ok := allocateTextureSlot()
+	if !ok {
 		return nil, fmt.Errorf("max texture count reached: %d", maxTextures)
 	}
-	defer func() {
-		textureCount += 1
-	}()
 
 	// TODO: handle opengl texture registration errors
 	var id uint32
@@ -51,14 +69,37 @@ func NewNRGBATexture(filepath string) (*texture, error) {
 
 	texture := &texture{
 		id:    id,
-		index: textureCount,
-		unit:  uint32(gl.TEXTURE0 + textureCount),
+		index: slot,
+		unit:  uint32(gl.TEXTURE0 + slot),
 	}
 	texture.setFromNRGBA(rgba)
+	atomic.AddInt64(&liveTextures, 1)
 
 	return texture, nil
 }
 
+func allocateTextureSlot() (uint32, bool) {
+	for slot := uint32(0); slot < maxTextures; slot++ {
+		if !textureSlots[slot] {
+			textureSlots[slot] = true
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// Delete frees the texture and its slot. The texture
+// must not be used afterwards.
+func (t *texture) Delete() {
+	if t.id == 0 {
+		return
+	}
+	gl.DeleteTextures(1, &t.id)
+	t.id = 0
+	textureSlots[t.index] = false
+	atomic.AddInt64(&liveTextures, -1)
+}
+
 // Bind implements the Binder interface.
 func (t *texture) Bind() {
 	//fmt.Printf("BIND [index: %v, unit: %v, id: %v]\n", t.index, t.unit, t.id)
@@ -88,6 +129,11 @@ func (t *texture) Unit() uint32 {
 	return t.unit
 }
 
+// SetNRGBA replaces the texture content, keeping its id and unit.
+func (t *texture) SetNRGBA(data *image.NRGBA) {
+	t.setFromNRGBA(data)
+}
+
 func (t *texture) setFromNRGBA(data *image.NRGBA) {
 	t.Bind()
 
@@ -111,14 +157,23 @@ func (t *texture) setFromNRGBA(data *image.NRGBA) {
 	t.Unbind()
 }
 
-func rgbaFromFile(filepath string) (*image.NRGBA, error) {
+// LoadNRGBA reads and decodes the image file at filepath,
+// flipped vertically to match the OpenGL texture coordinates.
+// It does not make GL calls, so it can be used from any goroutine.
+func LoadNRGBA(filepath string) (*image.NRGBA, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading texture file: %s", err)
 	}
 	defer reader.Close()
 
-	img, _, err := image.Decode(reader)
+	return DecodeNRGBA(reader)
+}
+
+// DecodeNRGBA decodes an image, flipped vertically to match
+// the OpenGL texture coordinates.
+func DecodeNRGBA(r io.Reader) (*image.NRGBA, error) {
+	img, _, err := image.Decode(r)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding texture file: %s", err)
 	}
@@ -1,6 +1,10 @@
 package opengl
 
-import "github.com/go-gl/gl/v4.6-core/gl"
+import (
+	"sync/atomic"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+)
 
 // VAO .
 type VAO struct {
@@ -12,6 +16,7 @@ type VAO struct {
 func NewVAO() *VAO {
 	var vaoID uint32
 	gl.GenVertexArrays(1, &vaoID)
+	atomic.AddInt64(&liveVAOs, 1)
 	return &VAO{id: vaoID}
 }
 
@@ -1,6 +1,7 @@
 package opengl
 
 import (
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
@@ -42,6 +43,7 @@ func NewVBO(size int) (*VBO, error) {
 	vbo.Bind()
 	gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(nil), gl.DYNAMIC_DRAW)
 	vbo.Unbind()
+	atomic.AddInt64(&liveVBOs, 1)
 
 	return vbo, nil
 }
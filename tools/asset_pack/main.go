@@ -0,0 +1,129 @@
+// Command asset_pack builds, lists and verifies asset packs.
+//
+//	asset_pack build -o assets.pak assets/
+//	asset_pack list assets.pak
+//	asset_pack verify assets.pak
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/devodev/opengl-experiment/internal/engine/asset/pack"
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "build":
+		err = build(args)
+	case "list":
+		err = list(args)
+	case "verify":
+		err = verify(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: asset_pack <command> [arguments]
+
+commands:
+  build -o <pack> <dir>   pack every file of dir
+  list <pack>             list the files of a pack
+  verify <pack>           check the checksums of a pack
+`)
+}
+
+func build(args []string) error {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	output := flags.String("o", "assets.pak", "output pack file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("build expects a single directory")
+	}
+	dir := flags.Arg(0)
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	entries, err := pack.WriteFS(f, os.DirFS(dir))
+	if err != nil {
+		f.Close()
+		os.Remove(*output)
+		return fmt.Errorf("error building asset pack: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	var size, compressedSize int64
+	for _, e := range entries {
+		size += e.Size
+		compressedSize += e.CompressedSize
+	}
+	fmt.Printf("packed %d files from %s into %s (%d -> %d bytes)\n", len(entries), dir, *output, size, compressedSize)
+	return nil
+}
+
+func list(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("list expects a single pack file")
+	}
+	p, err := pack.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	fmt.Printf("version %d\n", p.Version())
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tPACKED\tCOMPRESSION\tCRC32\tMODIFIED")
+	for _, e := range p.Entries() {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%08x\t%s\n",
+			e.Name, e.Size, e.CompressedSize, e.Compression, e.CRC32, e.ModTime.Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func verify(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("verify expects a single pack file")
+	}
+	p, err := pack.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	if err := p.Verify(); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d files ok\n", args[0], len(p.Entries()))
+	return nil
+}